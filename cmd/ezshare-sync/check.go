@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// runCheck pings the card, or with --probe also measures listing latency and throughput.
+// It exits with status 1 if the card is unreachable, and 2 if it answers but a probe
+// step fails, so scheduled jobs can tell a sleeping card from a slow one.
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		timeout = fs.Duration("timeout", 5*time.Second, "Timeout for the whole check")
+		probe   = fs.Bool("probe", false, "Also measure listing latency and download throughput")
+	)
+	cf.parse(fs, args)
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if !*probe {
+		ping, err := client.Ping(ctx)
+		if err != nil {
+			fatal("card unreachable", "url", cf.baseURL, "error", err)
+		}
+		printPing(ping)
+		return
+	}
+
+	result, err := client.Probe(ctx)
+	if result == nil {
+		fatal("card unreachable", "url", cf.baseURL, "error", err)
+	}
+	printPing(&result.PingResult)
+	if result.ListingLatency > 0 {
+		fmt.Printf("listing:    %dms\n", result.ListingLatency.Milliseconds())
+	}
+	if result.BytesRead > 0 {
+		fmt.Printf("throughput: %s/s (%s of %s in %dms)\n", formatBytes(int64(result.BytesPerSecond)),
+			formatBytes(result.BytesRead), result.File, result.ReadDuration.Milliseconds())
+	} else if err == nil {
+		fmt.Println("throughput: not measured, no file of at least 16 KiB in /")
+	}
+	if err != nil {
+		slog.Error("probe failed", "error", err)
+		os.Exit(2)
+	}
+}
+
+func printPing(ping *ezshare.PingResult) {
+	fmt.Printf("card:       %s %s (%s, build %s)\n", ping.Version.ChipModel, ping.Version.FirmwareVersion,
+		ping.Version.Date, ping.Version.BuildNumber)
+	fmt.Printf("latency:    %dms\n", ping.Latency.Milliseconds())
+}
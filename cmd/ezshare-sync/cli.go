@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// clientFlags are the flags shared by all commands that talk to a card.
+type clientFlags struct {
+	baseURL    string
+	proxyAddr  string
+	firmware   string
+	retries    int
+	strictList bool
+	deviceTZ   string
+	logLevel   string
+	logFormat  string
+	timeout    time.Duration
+	configPath string
+	profile    string
+
+	// location is the parsed --device-tz, set by newClient.
+	location *time.Location
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.baseURL, "url", "http://192.168.4.1", "EZ-Share base URL")
+	fs.StringVar(&f.proxyAddr, "proxy", "", "SOCKS5 proxy address (e.g., localhost:1080)")
+	fs.StringVar(&f.firmware, "firmware", "", "Firmware driver to use (default: detect from the device)")
+	fs.IntVar(&f.retries, "retries", 3, "Maximum number of retries for failed requests")
+	fs.BoolVar(&f.strictList, "strict-listings", false, "Fail a whole directory if any listing line can't be parsed")
+	fs.StringVar(&f.deviceTZ, "device-tz", "Local", "Time zone of the card's clock (e.g. Europe/Berlin, UTC)")
+	fs.DurationVar(&f.timeout, "request-timeout", 10*time.Minute, "Timeout for each request to the card, including the transfer")
+	f.registerLogging(fs)
+	f.registerConfig(fs)
+}
+
+// registerLogging registers the logging flags on their own, for commands such as emulate
+// that create no client.
+func (f *clientFlags) registerLogging(fs *flag.FlagSet) {
+	fs.StringVar(&f.logLevel, "log-level", "info", "Log level: debug, info, warn, or error")
+	fs.StringVar(&f.logFormat, "log-format", "text", "Log format: text or json")
+}
+
+// registerConfig registers the flags that select the configuration profile used by parse.
+func (f *clientFlags) registerConfig(fs *flag.FlagSet) {
+	fs.StringVar(&f.configPath, "config", "", "Configuration file (default: $XDG_CONFIG_HOME/ezshare-sync/config.yaml)")
+	fs.StringVar(&f.profile, "profile", "", "Profile of the configuration file to use (default: its default-profile)")
+}
+
+// parse parses args with fs, which must have the flags registered, and then fills in the
+// flags not given on the command line from the configuration profile. It returns the
+// positional arguments.
+func (f *clientFlags) parse(fs *flag.FlagSet, args []string) []string {
+	rest := parseArgs(fs, args)
+	if err := applyProfile(fs, f.configPath, f.profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	return rest
+}
+
+// setupLogging creates the logger selected by the flags, writing to w, and makes it the default.
+func (f *clientFlags) setupLogging(w io.Writer) *slog.Logger {
+	logger, err := newLogger(w, f.logLevel, f.logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+	return logger
+}
+
+// newClient creates a client configured by the flags, followed by extra options.
+func (f *clientFlags) newClient(extra ...ezshare.Option) *ezshare.Client {
+	location, err := time.LoadLocation(f.deviceTZ)
+	if err != nil {
+		fatal("invalid device time zone", "tz", f.deviceTZ, "error", err)
+	}
+	f.location = location
+
+	opts := []ezshare.Option{
+		ezshare.WithRetries(f.retries),
+		ezshare.WithTimeout(f.timeout),
+		ezshare.WithDeviceLocation(location),
+	}
+	if f.proxyAddr != "" {
+		opts = append(opts, ezshare.WithSOCKS5Proxy(f.proxyAddr))
+	}
+	if f.firmware != "" {
+		fw, err := ezshare.LookupFirmware(f.firmware)
+		if err != nil {
+			fatal("unknown firmware", "error", err)
+		}
+		opts = append(opts, ezshare.WithFirmware(fw))
+	}
+	if !f.strictList {
+		opts = append(opts, ezshare.WithLenientListings())
+	}
+	opts = append(opts, ezshare.WithSlog(slog.Default()))
+	opts = append(opts, extra...)
+
+	client, err := ezshare.NewClient(f.baseURL, opts...)
+	if err != nil {
+		fatal("failed to create client", "error", err)
+	}
+	return client
+}
+
+// parseArgs parses args with fs, allowing flags to follow positional arguments
+// (e.g. "ls /DATALOG -l"), and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
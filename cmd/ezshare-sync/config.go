@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the contents of the configuration file. Each profile maps flag names
+// (without dashes) to values; repeatable flags such as "filter" take a list. Profiles are
+// kept as YAML nodes, so that their settings can be applied in the order of the file.
+type configFile struct {
+	DefaultProfile string               `yaml:"default-profile"`
+	Profiles       map[string]yaml.Node `yaml:"profiles"`
+}
+
+// profileKeys are the flags that can be set in a profile. Filter rules are set with
+// "filter" rather than "include" and "exclude", to keep them in order.
+// nolint: gochecknoglobals
+var profileKeys = map[string]bool{
+	"url": true, "proxy": true, "firmware": true, "retries": true, "request-timeout": true,
+	"strict-listings": true, "device-tz": true, "log-level": true, "log-format": true,
+	"target": true, "dry-run": true, "exact-size": true, "parallel-ranges": true, "progress": true,
+	"jobs": true, "adaptive": true, "filter": true, "filter-from": true,
+}
+
+// repeatableKeys are the profile keys whose values add to those from the command line
+// rather than being overridden by them.
+// nolint: gochecknoglobals
+var repeatableKeys = map[string]bool{"filter": true, "filter-from": true}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/ezshare-sync/config.yaml, or
+// ~/.config/ezshare-sync/config.yaml if XDG_CONFIG_HOME is not set.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ezshare-sync", "config.yaml")
+}
+
+func loadConfig(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config configFile
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// applyProfile sets the flags of fs from a profile of the configuration file, in the
+// order of the file, except those given on the command line. Values of repeatable flags
+// are added after the ones from the command line, so that command line filter rules take
+// precedence.
+//
+// The profile is the one named by --profile, or else the file's default profile. A
+// missing configuration file is only an error if a profile was asked for.
+func applyProfile(fs *flag.FlagSet, configPath, profileName string) error {
+	explicitPath := configPath != ""
+	if !explicitPath {
+		configPath = defaultConfigPath()
+	}
+	config, err := loadConfig(configPath)
+	if errors.Is(err, os.ErrNotExist) && !explicitPath && profileName == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if profileName == "" {
+		profileName = config.DefaultProfile
+		if profileName == "" {
+			return nil
+		}
+	}
+	profile, ok := config.Profiles[profileName]
+	if !ok {
+		return fmt.Errorf("%s: no profile %q", configPath, profileName)
+	}
+	if profile.Kind != yaml.MappingNode && profile.Tag != "!!null" {
+		return fmt.Errorf("%s: profile %q is not a mapping", configPath, profileName)
+	}
+
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	// A mapping node holds its keys and values alternately
+	for i := 0; i+1 < len(profile.Content); i += 2 {
+		key := profile.Content[i].Value
+		var value any
+		if err := profile.Content[i+1].Decode(&value); err != nil {
+			return fmt.Errorf("%s: profile %q: %s: %w", configPath, profileName, key, err)
+		}
+		if !profileKeys[key] {
+			return fmt.Errorf("%s: profile %q: unknown setting %q", configPath, profileName, key)
+		}
+		// Settings for other commands, such as target for ls, don't apply
+		if fs.Lookup(key) == nil || given[key] && !repeatableKeys[key] {
+			continue
+		}
+		values, isList := value.([]any)
+		if !isList {
+			values = []any{value}
+		}
+		for _, v := range values {
+			if err := fs.Set(key, expandHome(fmt.Sprint(v))); err != nil {
+				return fmt.Errorf("%s: profile %q: %s: %w", configPath, profileName, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(value string) string {
+	if !strings.HasPrefix(value, "~/") {
+		return value
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return value
+	}
+	return filepath.Join(home, value[2:])
+}
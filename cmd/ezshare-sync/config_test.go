@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func newProfileFlagSet() (*flag.FlagSet, *string, *int, *filter) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	url := fs.String("url", "http://ezshare.card", "")
+	jobs := fs.Int("jobs", 1, "")
+	filters := &filter{}
+	filters.register(fs)
+	return fs, url, jobs, filters
+}
+
+func filterPatterns(f *filter) []string {
+	var patterns []string
+	for _, rule := range f.rules {
+		pattern := strings.Join(rule.components, "/")
+		if rule.include {
+			pattern = "+ " + pattern
+		} else {
+			pattern = "- " + pattern
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+const testConfig = `
+default-profile: home
+profiles:
+  home:
+    url: http://192.168.4.1
+    jobs: 4
+    filter:
+      - "+ /STR.edf"
+      - "- *"
+`
+
+func TestApplyProfile(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	fs, url, jobs, filters := newProfileFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyProfile(fs, path, ""); err != nil {
+		t.Fatalf("applyProfile failed: %v", err)
+	}
+	if *url != "http://192.168.4.1" || *jobs != 4 {
+		t.Errorf("got url %q, jobs %d", *url, *jobs)
+	}
+	if got, want := filterPatterns(filters), []string{"+ str.edf", "- *"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filter patterns = %q, want %q", got, want)
+	}
+}
+
+func TestApplyProfile_FlagsTakePrecedence(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	fs, url, jobs, filters := newProfileFlagSet()
+	if err := fs.Parse([]string{"-jobs", "2", "-filter", "+ /DATALOG/**"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyProfile(fs, path, "home"); err != nil {
+		t.Fatalf("applyProfile failed: %v", err)
+	}
+	// A scalar flag from the command line overrides the profile
+	if *jobs != 2 {
+		t.Errorf("jobs = %d, want 2 from the command line", *jobs)
+	}
+	if *url != "http://192.168.4.1" {
+		t.Errorf("url = %q, want the profile's", *url)
+	}
+	// Repeatable flags add the profile's rules after the command line's
+	want := []string{"+ datalog/**", "+ str.edf", "- *"}
+	if got := filterPatterns(filters); !reflect.DeepEqual(got, want) {
+		t.Errorf("filter patterns = %q, want %q", got, want)
+	}
+}
+
+func TestApplyProfile_FileOrder(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules")
+	if err := os.WriteFile(rules, []byte("+ /DATALOG/**\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, `
+profiles:
+  home:
+    filter-from: `+rules+`
+    filter:
+      - "+ /STR.edf"
+      - "- *"
+`)
+	fs, _, _, filters := newProfileFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyProfile(fs, path, "home"); err != nil {
+		t.Fatalf("applyProfile failed: %v", err)
+	}
+	// filter-from comes first in the file, so its rules do too
+	want := []string{"+ datalog/**", "+ str.edf", "- *"}
+	if got := filterPatterns(filters); !reflect.DeepEqual(got, want) {
+		t.Errorf("filter patterns = %q, want %q", got, want)
+	}
+}
+
+func TestApplyProfile_Errors(t *testing.T) {
+	path := writeConfig(t, "profiles:\n  bad:\n    include: x\n  list:\n    - url\n")
+	tests := []struct {
+		name       string
+		configPath string
+		profile    string
+	}{
+		{"unknown profile", path, "missing"},
+		{"unknown setting", path, "bad"},
+		{"not a mapping", path, "list"},
+		{"missing file", filepath.Join(t.TempDir(), "none.yaml"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, _, _, _ := newProfileFlagSet()
+			if err := applyProfile(fs, tt.configPath, tt.profile); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
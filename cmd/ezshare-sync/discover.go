@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// runDiscover looks for EZ-Share cards at their usual addresses (or the hosts given as
+// arguments), and optionally on the local subnets, and prints the ones that answer.
+func runDiscover(args []string) {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	var cf clientFlags
+	cf.registerLogging(fs)
+	cf.registerConfig(fs)
+	fs.StringVar(&cf.proxyAddr, "proxy", "", "SOCKS5 proxy address (e.g., localhost:1080)")
+	var (
+		scan    = fs.Bool("scan", false, "Also scan the local /24 subnets of every network interface")
+		timeout = fs.Duration("timeout", 2*time.Second, "Timeout for each probe")
+	)
+	hosts := cf.parse(fs, args)
+
+	cf.setupLogging(os.Stderr)
+
+	opts := ezshare.DiscoverOptions{
+		Hosts:       hosts,
+		ScanSubnets: *scan,
+		Timeout:     *timeout,
+	}
+	if cf.proxyAddr != "" {
+		opts.ClientOptions = append(opts.ClientOptions, ezshare.WithSOCKS5Proxy(cf.proxyAddr))
+	}
+
+	devices, err := ezshare.Discover(context.Background(), opts)
+	if err != nil {
+		fatal("discovery failed", "error", err)
+	}
+	if len(devices) == 0 {
+		fatal("no EZ-Share card found")
+	}
+
+	for _, device := range devices {
+		fmt.Printf("%-22s %6dms  %s %s (%s, build %s)\n", device.Address, device.Latency.Milliseconds(),
+			device.Version.ChipModel, device.Version.FirmwareVersion, device.Version.Date, device.Version.BuildNumber)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// runDu prints the size of each directory under a path, as the sum of the file sizes
+// in listings. Those are rounded up to KB, so totals are an upper bound.
+func runDu(args []string) {
+	fs := flag.NewFlagSet("du", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		summary  = fs.Bool("s", false, "Print only the total for the path")
+		human    = fs.Bool("h", false, "Print sizes in KiB, MiB, ...")
+		maxDepth = fs.Int("d", -1, "Print totals only for directories this many levels deep (-1: all)")
+	)
+	root := "/"
+	if rest := cf.parse(fs, args); len(rest) > 1 {
+		fatal("du takes at most one path")
+	} else if len(rest) == 1 {
+		root = rest[0]
+	}
+	if *summary {
+		*maxDepth = 0
+	}
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+
+	tree, err := loadTree(context.Background(), client, root)
+	if err != nil {
+		fatal("failed to list", "path", root, "error", err)
+	}
+
+	format := func(n int64) string { return strconv.FormatInt(n, 10) }
+	if *human {
+		format = formatBytes
+	}
+	if !printDu(tree, 0, *maxDepth, format) {
+		os.Exit(1)
+	}
+}
+
+// printDu prints the totals of node and the directories under it, deepest first, and
+// reports whether all of them could be listed.
+func printDu(node *treeNode, depth, maxDepth int, format func(int64) string) bool {
+	ok := node.err == nil
+	for _, child := range node.children {
+		if child.entry.IsDir {
+			ok = printDu(child, depth+1, maxDepth, format) && ok
+		}
+	}
+	if maxDepth < 0 || depth <= maxDepth {
+		fmt.Printf("%s\t%s\n", format(node.size()), node.entry.Path)
+	}
+	return ok
+}
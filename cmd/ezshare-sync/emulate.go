@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/haimgel/ezshare-sync/ezshare/ezsharetest"
+)
+
+// runEmulate serves a local directory as a fake EZ-Share card, so the tool can be
+// exercised against a copy of an SD card without the hardware.
+func runEmulate(args []string) {
+	fs := flag.NewFlagSet("emulate", flag.ExitOnError)
+	var cf clientFlags
+	cf.registerLogging(fs)
+	var (
+		rootDir  = fs.String("root", "", "Local directory to serve as the card contents (required)")
+		listen   = fs.String("listen", ":8080", "Address to listen on")
+		hrefHost = fs.String("href-host", "", "Host to embed in download links (default: the Host header of each request)")
+		version  = fs.String("device-version", ezsharetest.DefaultVersion, "Version string reported by the emulated card")
+	)
+	_ = fs.Parse(args)
+
+	cf.setupLogging(os.Stderr)
+
+	if *rootDir == "" {
+		fatal("--root flag is required")
+	}
+	if info, err := os.Stat(*rootDir); err != nil || !info.IsDir() {
+		fatal("not a directory", "root", *rootDir)
+	}
+
+	handler := ezsharetest.NewHandler(*rootDir)
+	handler.HrefHost = *hrefHost
+	handler.Version = *version
+
+	slog.Info("emulating EZ-Share card", "root", *rootDir, "listen", *listen)
+	if err := http.ListenAndServe(*listen, handler); err != nil {
+		fatal("emulator failed", "error", err)
+	}
+}
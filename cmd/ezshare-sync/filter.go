@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// filterRule is an --include or --exclude rule.
+type filterRule struct {
+	include bool
+	// components is the lowercased pattern split at slashes; "**" matches any number of
+	// path components, including none.
+	components []string
+	// anchored patterns start with a slash and match from the root; others match at any depth.
+	anchored bool
+	// dirOnly patterns end with a slash and match directories only.
+	dirOnly bool
+}
+
+func newFilterRule(include bool, pattern string) (filterRule, error) {
+	rule := filterRule{include: include}
+	p := strings.ToLower(strings.TrimSpace(pattern))
+	if strings.HasSuffix(p, "/") {
+		rule.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if strings.HasPrefix(p, "/") {
+		rule.anchored = true
+		p = strings.TrimLeft(p, "/")
+	}
+	if p == "" {
+		return rule, fmt.Errorf("empty pattern %q", pattern)
+	}
+	rule.components = strings.Split(p, "/")
+	for _, component := range rule.components {
+		if _, err := path.Match(component, ""); err != nil {
+			return rule, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return rule, nil
+}
+
+// matches reports whether the rule matches the path components of an entry.
+func (r *filterRule) matches(components []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return matchComponents(r.components, components)
+	}
+	for i := range components {
+		if matchComponents(r.components, components[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesBelow reports whether the rule could match an entry under the directory with
+// the given path components.
+func (r *filterRule) matchesBelow(components []string) bool {
+	if !r.anchored {
+		// The pattern can match starting at any depth, including below the directory
+		return true
+	}
+	return matchPrefix(r.components, components)
+}
+
+func matchComponents(pattern, components []string) bool {
+	if len(pattern) == 0 {
+		return len(components) == 0
+	}
+	if pattern[0] == "**" {
+		return matchComponents(pattern[1:], components) ||
+			len(components) > 0 && matchComponents(pattern, components[1:])
+	}
+	if len(components) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], components[0])
+	return matched && matchComponents(pattern[1:], components[1:])
+}
+
+// matchPrefix reports whether components match the start of pattern, with some of the
+// pattern left over to match deeper components.
+func matchPrefix(pattern, components []string) bool {
+	if len(components) == 0 {
+		return len(pattern) > 0
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return matchPrefix(pattern[1:], components) || matchPrefix(pattern, components[1:])
+	}
+	matched, _ := path.Match(pattern[0], components[0])
+	return matched && matchPrefix(pattern[1:], components[1:])
+}
+
+// filter decides which remote paths are synced, from rsync-style rules: the first rule
+// that matches a path decides whether it is included or excluded, and paths that match
+// no rule are included. Matching is case-insensitive, as on FAT. Unlike rsync, an include
+// rule also matches the directories leading to what it includes, so that
+// "--include /DATALOG/2026*/** --exclude *" doesn't exclude /DATALOG itself.
+type filter struct {
+	rules []filterRule
+}
+
+// excluded reports whether the entry at the remote path p is excluded.
+func (f *filter) excluded(p string, isDir bool) bool {
+	if f == nil || len(f.rules) == 0 {
+		return false
+	}
+	components := strings.Split(strings.Trim(strings.ToLower(p), "/"), "/")
+	for i := range f.rules {
+		rule := &f.rules[i]
+		if rule.matches(components, isDir) || isDir && rule.include && rule.matchesBelow(components) {
+			return !rule.include
+		}
+	}
+	return false
+}
+
+func (f *filter) add(include bool, pattern string) error {
+	rule, err := newFilterRule(include, pattern)
+	if err != nil {
+		return err
+	}
+	f.rules = append(f.rules, rule)
+	return nil
+}
+
+// addFile adds the rules from a filter file, one per line in the format of addRule.
+// Blank lines and lines starting with "#" or ";" are ignored.
+func (f *filter) addFile(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if err := f.addRule(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// addRule adds a rule written as "+ PATTERN" to include, or "- PATTERN" or just
+// "PATTERN" to exclude.
+func (f *filter) addRule(rule string) error {
+	switch {
+	case strings.HasPrefix(rule, "+ "):
+		return f.add(true, rule[2:])
+	case strings.HasPrefix(rule, "- "):
+		return f.add(false, rule[2:])
+	default:
+		return f.add(false, rule)
+	}
+}
+
+// filterFlag is a flag.Value that adds rules to a filter. Include, exclude, and filter
+// file flags share the filter, so rules keep the order in which they were given.
+type filterFlag struct {
+	filter *filter
+	add    func(f *filter, value string) error
+}
+
+func (v filterFlag) String() string { return "" }
+
+func (v filterFlag) Set(value string) error { return v.add(v.filter, value) }
+
+// register adds the --include, --exclude, --filter, and --filter-from flags.
+func (f *filter) register(fs *flag.FlagSet) {
+	fs.Var(filterFlag{f, func(f *filter, p string) error { return f.add(true, p) }}, "include",
+		"Sync paths matching this glob pattern, even if a later rule excludes them (repeatable)")
+	fs.Var(filterFlag{f, func(f *filter, p string) error { return f.add(false, p) }}, "exclude",
+		"Skip paths matching this glob pattern, unless an earlier rule includes them (repeatable)")
+	fs.Var(filterFlag{f, (*filter).addRule}, "filter",
+		"Add a rule: '+ PATTERN' to include, '- PATTERN' to exclude (repeatable)")
+	fs.Var(filterFlag{f, (*filter).addFile}, "filter-from",
+		"Read include (+ PATTERN) and exclude (- PATTERN) rules from a file (repeatable)")
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestFilter(t *testing.T, rules ...string) *filter {
+	t.Helper()
+	f := &filter{}
+	for _, rule := range rules {
+		if err := f.addRule(rule); err != nil {
+			t.Fatalf("Failed to add rule %q: %v", rule, err)
+		}
+	}
+	return f
+}
+
+func TestFilter_Excluded(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		// Skipping the card's own files
+		{"dcim", []string{"- DCIM", "- System Volume Information", "- ezshare.cfg"}, "/DCIM", true, true},
+		{"system volume information", []string{"- DCIM", "- System Volume Information", "- ezshare.cfg"}, "/System Volume Information", true, true},
+		{"ezshare.cfg", []string{"- DCIM", "- System Volume Information", "- ezshare.cfg"}, "/ezshare.cfg", false, true},
+		{"other files kept", []string{"- DCIM", "- System Volume Information", "- ezshare.cfg"}, "/DATALOG/20260104/BRP.edf", false, false},
+		{"unanchored at depth", []string{"- DCIM"}, "/A/DCIM", true, true},
+
+		// Syncing only DATALOG/** and STR.edf
+		{"datalog dir", []string{"+ DATALOG/**", "+ STR.edf", "- *"}, "/DATALOG", true, false},
+		{"datalog file", []string{"+ DATALOG/**", "+ STR.edf", "- *"}, "/DATALOG/20260104/BRP.edf", false, false},
+		{"str.edf", []string{"+ DATALOG/**", "+ STR.edf", "- *"}, "/STR.edf", false, false},
+		{"other file", []string{"+ DATALOG/**", "+ STR.edf", "- *"}, "/Identification.tgt", false, true},
+		{"nested datalog", []string{"+ DATALOG/**", "- *"}, "/A", true, false},
+		{"nested datalog file", []string{"+ DATALOG/**", "- *"}, "/A/DATALOG/x.edf", false, false},
+		{"anchored", []string{"+ /DATALOG/**", "- *"}, "/A", true, true},
+
+		// Directories leading to an include are kept
+		{"leading dir", []string{"+ /DATALOG/202601*/**", "- *"}, "/DATALOG", true, false},
+		{"leading dir other", []string{"+ /DATALOG/202601*/**", "- *"}, "/SETTINGS", true, true},
+		{"sibling excluded", []string{"+ /DATALOG/202601*/**", "- *"}, "/DATALOG/20251231", true, true},
+		{"unanchored keeps dirs", []string{"+ *.edf", "- *"}, "/DATALOG", true, false},
+		{"unanchored file", []string{"+ *.edf", "- *"}, "/DATALOG/20260104/BRP.edf", false, false},
+		{"unanchored other file", []string{"+ *.edf", "- *"}, "/DATALOG/20260104/x.crc", false, true},
+
+		// ** in the middle
+		{"middle zero", []string{"- /DATALOG/**/*.crc"}, "/DATALOG/x.crc", false, true},
+		{"middle many", []string{"- /DATALOG/**/*.crc"}, "/DATALOG/a/b/x.crc", false, true},
+		{"middle other", []string{"- /DATALOG/**/*.crc"}, "/SETTINGS/x.crc", false, false},
+
+		// Case folding
+		{"case dir", []string{"- dcim"}, "/DCIM", true, true},
+		{"case pattern", []string{"- /STR.EDF"}, "/str.edf", false, true},
+		{"case class", []string{"- [A-C]*.edf"}, "/brp.EDF", false, true},
+
+		// Directory-only patterns and first match wins
+		{"dir only dir", []string{"- tmp/"}, "/x/tmp", true, true},
+		{"dir only file", []string{"- tmp/"}, "/x/tmp", false, false},
+		{"first match wins", []string{"+ /STR.edf", "- *.edf"}, "/STR.edf", false, false},
+		{"no rules match", []string{"- *.crc"}, "/STR.edf", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFilter(t, tt.rules...)
+			if got := f.excluded(tt.path, tt.isDir); got != tt.excluded {
+				t.Errorf("excluded(%q) = %v, want %v", tt.path, got, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestFilter_InvalidPattern(t *testing.T) {
+	f := &filter{}
+	if err := f.add(false, "[bad"); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+	if err := f.add(false, "/"); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+}
+
+func TestFilter_AddFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "rules")
+	content := "# comment\n; comment\n\n+ /DATALOG/**\n- *\n"
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+
+	f := &filter{}
+	if err := f.addFile(name); err != nil {
+		t.Fatalf("addFile failed: %v", err)
+	}
+	if len(f.rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(f.rules))
+	}
+	if f.excluded("/DATALOG/x.edf", false) || !f.excluded("/STR.edf", false) {
+		t.Error("rules from the file were not applied in order")
+	}
+}
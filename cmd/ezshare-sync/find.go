@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// findPredicate is a size or age test in the style of find(1): "+N" matches values
+// greater than N, "-N" less than N, and "N" exactly N, after rounding the value up
+// to the unit.
+type findPredicate struct {
+	cmp  int // -1, 0, or +1
+	n    int64
+	unit int64
+}
+
+func parsePredicate(s string, units map[byte]int64, defaultUnit int64) (*findPredicate, error) {
+	p := &findPredicate{unit: defaultUnit}
+	switch {
+	case strings.HasPrefix(s, "+"):
+		p.cmp, s = 1, s[1:]
+	case strings.HasPrefix(s, "-"):
+		p.cmp, s = -1, s[1:]
+	}
+	if s != "" {
+		if unit, ok := units[s[len(s)-1]]; ok {
+			p.unit, s = unit, s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid value %q", s)
+	}
+	p.n = n
+	return p, nil
+}
+
+func (p *findPredicate) match(value int64) bool {
+	rounded := (value + p.unit - 1) / p.unit
+	switch p.cmp {
+	case 1:
+		return rounded > p.n
+	case -1:
+		return rounded < p.n
+	default:
+		return rounded == p.n
+	}
+}
+
+// findOptions are the tests an entry must pass to be printed by find.
+type findOptions struct {
+	name  string
+	kind  string
+	size  *findPredicate
+	mtime *findPredicate
+	now   time.Time
+}
+
+func (o *findOptions) match(entry *ezshare.Entry) bool {
+	if o.name != "" {
+		// FAT names are case-insensitive
+		matched, _ := path.Match(strings.ToLower(o.name), strings.ToLower(entry.Name))
+		if !matched {
+			return false
+		}
+	}
+	if o.kind == "f" && entry.IsDir || o.kind == "d" && !entry.IsDir {
+		return false
+	}
+	if o.size != nil && (entry.IsDir || !o.size.match(entry.Size)) {
+		return false
+	}
+	if o.mtime != nil {
+		// find(1) counts whole days of age, ignoring fractions
+		days := int64(o.now.Sub(entry.Timestamp) / (24 * time.Hour))
+		if !o.mtime.match(days) {
+			return false
+		}
+	}
+	return true
+}
+
+// runFind prints the paths of the entries under a directory that pass all the given tests.
+func runFind(args []string) {
+	fs := flag.NewFlagSet("find", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		name  = fs.String("name", "", "Match names against a glob pattern, case-insensitively (e.g. '*_BRP.edf')")
+		kind  = fs.String("type", "", "Match only files (f) or directories (d)")
+		size  = fs.String("size", "", "Match file sizes: +N larger than, -N smaller than, N exactly; suffix k, M, or G (default: bytes)")
+		mtime = fs.String("mtime", "", "Match modification times: +N more than N days ago, -N less than N days ago, N exactly")
+		long  = fs.Bool("l", false, "Show type, size, and modification time")
+	)
+	root := "/"
+	if rest := cf.parse(fs, args); len(rest) > 1 {
+		fatal("find takes at most one path")
+	} else if len(rest) == 1 {
+		root = rest[0]
+	}
+
+	cf.setupLogging(os.Stderr)
+
+	opts := findOptions{name: *name, kind: *kind, now: time.Now()}
+	if *kind != "" && *kind != "f" && *kind != "d" {
+		fatal("invalid -type, use f or d", "type", *kind)
+	}
+	if _, err := path.Match(*name, ""); err != nil {
+		fatal("invalid -name pattern", "pattern", *name, "error", err)
+	}
+	var err error
+	if *size != "" {
+		opts.size, err = parsePredicate(*size, map[byte]int64{'k': 1 << 10, 'M': 1 << 20, 'G': 1 << 30}, 1)
+		if err != nil {
+			fatal("invalid -size", "error", err)
+		}
+	}
+	if *mtime != "" {
+		opts.mtime, err = parsePredicate(*mtime, nil, 1)
+		if err != nil {
+			fatal("invalid -mtime", "error", err)
+		}
+	}
+
+	client := cf.newClient()
+	root = path.Clean("/" + root)
+
+	failed := false
+	err = client.Walk(context.Background(), root, func(p string, entry *ezshare.Entry, err error) error {
+		if err != nil {
+			slog.Error("failed to list directory", "path", p, "error", err)
+			failed = true
+			return nil
+		}
+		if p == root || !opts.match(entry) {
+			return nil
+		}
+		if *long {
+			fmt.Println(formatLong(entry, p))
+		} else {
+			fmt.Println(p)
+		}
+		return nil
+	})
+	if err != nil {
+		fatal("find failed", "error", err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
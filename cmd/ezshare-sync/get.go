@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// runGet downloads a file, or with -r a directory tree, from the card. Unlike sync, it
+// always downloads, overwriting local files.
+func runGet(args []string) {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	recursive := fs.Bool("r", false, "Download a directory and everything under it")
+	rest := cf.parse(fs, args)
+	if len(rest) < 1 || len(rest) > 2 {
+		fmt.Fprintln(os.Stderr, "Usage: ezshare-sync get [flags] REMOTE [LOCAL]")
+		os.Exit(2)
+	}
+	remotePath := rest[0]
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+	ctx := context.Background()
+
+	entry, err := client.Stat(ctx, remotePath)
+	if err != nil {
+		fatal("failed to get", "path", remotePath, "error", err)
+	}
+	if entry.IsDir && !*recursive {
+		fatal("is a directory, use -r to download it", "path", entry.Path)
+	}
+
+	// Like cp, download into LOCAL if it is an existing directory
+	name := entry.Name
+	if entry.Path == "/" {
+		name = "."
+	}
+	localPath := name
+	if len(rest) == 2 {
+		localPath = rest[1]
+		if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+			localPath = filepath.Join(localPath, name)
+		}
+	}
+
+	if !entry.IsDir {
+		if err := getFile(ctx, client, entry, localPath); err != nil {
+			fatal("failed to download", "path", entry.Path, "error", err)
+		}
+		return
+	}
+	if errs := getTree(ctx, client, entry, localPath); errs > 0 {
+		fatal("some files could not be downloaded", "errors", errs)
+	}
+}
+
+// getTree downloads the directory tree at root into localBase, and returns the number
+// of files and directories that failed.
+func getTree(ctx context.Context, client *ezshare.Client, root *ezshare.Entry, localBase string) int {
+	errs := 0
+	var dirs []*ezshare.Entry
+	var dirPaths []string
+	err := client.Walk(ctx, root.Path, func(p string, entry *ezshare.Entry, err error) error {
+		if err != nil {
+			slog.Error("failed to list directory", "path", p, "error", err)
+			errs++
+			return nil
+		}
+		if p == root.Path {
+			entry = root
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, root.Path), "/")
+		localPath := filepath.Join(localBase, filepath.FromSlash(rel))
+
+		if entry.IsDir {
+			if err := os.MkdirAll(localPath, 0755); err != nil {
+				slog.Error("failed to create directory", "path", localPath, "error", err)
+				errs++
+				return ezshare.SkipDir
+			}
+			dirs = append(dirs, entry)
+			dirPaths = append(dirPaths, localPath)
+			return nil
+		}
+		if err := getFile(ctx, client, entry, localPath); err != nil {
+			slog.Error("failed to download", "path", p, "error", err)
+			errs++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to download", "path", root.Path, "error", err)
+		errs++
+	}
+
+	// Directory times change as files are added, so set them last, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if !dirs[i].Timestamp.IsZero() {
+			_ = os.Chtimes(dirPaths[i], dirs[i].Timestamp, dirs[i].Timestamp)
+		}
+	}
+	return errs
+}
+
+// getFile downloads entry to localPath through a temporary file, and sets its
+// modification time to the remote timestamp.
+func getFile(ctx context.Context, client *ezshare.Client, entry *ezshare.Entry, localPath string) error {
+	start := time.Now()
+	tempPath := localPath + ".tmp"
+	// Don't resume from whatever happens to be there
+	_ = os.Remove(tempPath)
+	if err := client.DownloadFile(ctx, entry, tempPath); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err := os.Chtimes(tempPath, entry.Timestamp, entry.Timestamp); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to set timestamp: %w", err)
+	}
+	if err := os.Rename(tempPath, localPath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	slog.Info("downloaded", "path", entry.Path, "local", localPath, "duration", time.Since(start))
+	return nil
+}
+
+// runCat writes files from the card to standard output.
+func runCat(args []string) {
+	fs := flag.NewFlagSet("cat", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	rest := cf.parse(fs, args)
+	if len(rest) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: ezshare-sync cat [flags] REMOTE...")
+		os.Exit(2)
+	}
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+	ctx := context.Background()
+
+	for _, remotePath := range rest {
+		if err := catFile(ctx, client, remotePath); err != nil {
+			fatal("failed to read", "path", remotePath, "error", err)
+		}
+	}
+}
+
+func catFile(ctx context.Context, client *ezshare.Client, remotePath string) (err error) {
+	entry, err := client.Stat(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	if entry.IsDir {
+		return errors.New("is a directory")
+	}
+
+	reader, err := client.GetFile(ctx, entry)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(os.Stdout, reader)
+	return err
+}
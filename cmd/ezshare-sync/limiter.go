@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+const (
+	// growAfter is the number of consecutive successes after which an adaptive limiter
+	// raises its limit by one.
+	growAfter = 10
+	// shrinkInterval is the minimum time between two reductions of an adaptive limit, so
+	// that a burst of failures from requests already in flight counts once.
+	shrinkInterval = 2 * time.Second
+)
+
+// limiter bounds the number of concurrent jobs. In adaptive mode, the limit is halved
+// when the card returns 5xx errors or times out, and raised back by one at a time, up
+// to the initial limit, as requests succeed again.
+type limiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	max      int
+	limit    int
+	active   int
+	adaptive bool
+
+	successes  int
+	lastShrink time.Time
+}
+
+func newLimiter(jobs int, adaptive bool) *limiter {
+	jobs = max(jobs, 1)
+	l := &limiter{max: jobs, limit: jobs, adaptive: adaptive}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a job may start.
+func (l *limiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active >= l.limit {
+		l.cond.Wait()
+	}
+	l.active++
+}
+
+// release ends a job started with acquire, and adapts the limit to its outcome.
+func (l *limiter) release(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	if err == nil {
+		l.succeededLocked()
+	} else {
+		l.failedLocked(err)
+	}
+	l.cond.Broadcast()
+}
+
+// failed reports an error that was retried, and so did not end the job.
+func (l *limiter) failed(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failedLocked(err)
+}
+
+func (l *limiter) succeededLocked() {
+	if !l.adaptive || l.limit >= l.max {
+		return
+	}
+	l.successes++
+	if l.successes >= growAfter {
+		l.successes = 0
+		l.limit++
+		slog.Debug("card recovering, raising concurrency", "jobs", l.limit)
+	}
+}
+
+func (l *limiter) failedLocked(err error) {
+	if !l.adaptive || !isOverloaded(err) {
+		return
+	}
+	l.successes = 0
+	if l.limit == 1 || time.Since(l.lastShrink) < shrinkInterval {
+		return
+	}
+	l.limit = max(l.limit/2, 1)
+	l.lastShrink = time.Now()
+	slog.Warn("card overloaded, reducing concurrency", "jobs", l.limit, "error", err)
+}
+
+// isOverloaded reports whether err suggests the card can't keep up: a 5xx response or a timeout.
+func isOverloaded(err error) bool {
+	if errors.Is(err, ezshare.ErrServerError) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
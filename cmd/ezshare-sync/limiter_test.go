@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+var errOverloaded = &ezshare.StatusError{Op: "download", Code: 503}
+
+func TestLimiter_Bound(t *testing.T) {
+	lim := newLimiter(3, false)
+
+	var active, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 12 {
+		lim.acquire()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			active.Add(-1)
+			lim.release(nil)
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p != 3 {
+		t.Errorf("peak concurrency = %d, want 3", p)
+	}
+}
+
+func TestLimiter_MinimumOneJob(t *testing.T) {
+	lim := newLimiter(0, true)
+	if lim.limit != 1 || lim.max != 1 {
+		t.Errorf("limit = %d, max = %d, want 1", lim.limit, lim.max)
+	}
+}
+
+func TestLimiter_AdaptiveShrink(t *testing.T) {
+	lim := newLimiter(8, true)
+
+	lim.failed(errOverloaded)
+	if lim.limit != 4 {
+		t.Fatalf("limit = %d after an overload, want 4", lim.limit)
+	}
+	// Failures of requests already in flight count once
+	lim.failed(errOverloaded)
+	lim.release(errOverloaded)
+	if lim.limit != 4 {
+		t.Errorf("limit = %d within shrinkInterval, want 4", lim.limit)
+	}
+
+	lim.lastShrink = time.Now().Add(-shrinkInterval)
+	lim.failed(errOverloaded)
+	if lim.limit != 2 {
+		t.Errorf("limit = %d after shrinkInterval, want 2", lim.limit)
+	}
+
+	// Errors that don't suggest an overloaded card are ignored
+	lim.lastShrink = time.Now().Add(-shrinkInterval)
+	lim.failed(ezshare.ErrNotFound)
+	lim.failed(errors.New("disk full"))
+	if lim.limit != 2 {
+		t.Errorf("limit = %d after other errors, want 2", lim.limit)
+	}
+
+	for range 3 {
+		lim.lastShrink = time.Now().Add(-shrinkInterval)
+		lim.failed(errOverloaded)
+	}
+	if lim.limit != 1 {
+		t.Errorf("limit = %d, want at least 1", lim.limit)
+	}
+}
+
+func TestLimiter_NotAdaptive(t *testing.T) {
+	lim := newLimiter(4, false)
+	lim.failed(errOverloaded)
+	lim.acquire()
+	lim.release(errOverloaded)
+	if lim.limit != 4 {
+		t.Errorf("limit = %d, want 4 without --adaptive", lim.limit)
+	}
+}
+
+func TestLimiter_AdaptiveRegrow(t *testing.T) {
+	lim := newLimiter(4, true)
+	lim.failed(errOverloaded)
+	if lim.limit != 2 {
+		t.Fatalf("limit = %d, want 2", lim.limit)
+	}
+
+	succeed := func(n int) {
+		for range n {
+			lim.acquire()
+			lim.release(nil)
+		}
+	}
+	succeed(growAfter - 1)
+	if lim.limit != 2 {
+		t.Errorf("limit = %d before growAfter successes, want 2", lim.limit)
+	}
+	// An overload resets the count, even when it doesn't shrink the limit
+	lim.failed(errOverloaded)
+	succeed(growAfter - 1)
+	if lim.limit != 2 {
+		t.Errorf("limit = %d after the count was reset, want 2", lim.limit)
+	}
+	succeed(1)
+	if lim.limit != 3 {
+		t.Errorf("limit = %d after growAfter successes, want 3", lim.limit)
+	}
+	succeed(3 * growAfter)
+	if lim.limit != 4 {
+		t.Errorf("limit = %d, want it capped at 4", lim.limit)
+	}
+}
+
+func TestLimiter_ShrinkBlocksAcquire(t *testing.T) {
+	lim := newLimiter(2, true)
+	lim.acquire()
+	lim.acquire()
+	lim.release(errOverloaded)
+
+	acquired := make(chan struct{})
+	go func() {
+		lim.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire should wait while the reduced limit is in use")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	lim.release(nil)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire should proceed once a job is released")
+	}
+}
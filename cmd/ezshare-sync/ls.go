@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// timeFormat is the format of timestamps in human-readable output.
+const timeFormat = "2006-01-02 15:04:05"
+
+// lsEntry is an entry in --json output.
+type lsEntry struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	ShortName string    `json:"short_name"`
+	IsDir     bool      `json:"is_dir"`
+	Size      int64     `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// runLs lists a directory on the card, or a single file.
+func runLs(args []string) {
+	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		long   = fs.Bool("l", false, "Show type, size, and modification time")
+		asJSON = fs.Bool("json", false, "Print entries as a JSON array")
+		asCSV  = fs.Bool("csv", false, "Print entries as CSV with a header row")
+	)
+	remotePath := "/"
+	if rest := cf.parse(fs, args); len(rest) > 1 {
+		fatal("ls takes at most one path")
+	} else if len(rest) == 1 {
+		remotePath = rest[0]
+	}
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+	ctx := context.Background()
+
+	entries, err := listPath(ctx, client, remotePath)
+	if err != nil {
+		fatal("failed to list", "path", remotePath, "error", err)
+	}
+
+	switch {
+	case *asJSON:
+		err = writeJSON(os.Stdout, entries)
+	case *asCSV:
+		err = writeCSV(os.Stdout, entries)
+	default:
+		for _, entry := range entries {
+			if *long {
+				fmt.Println(formatLong(entry, entry.Name))
+			} else {
+				fmt.Println(entry.Name)
+			}
+		}
+	}
+	if err != nil {
+		fatal("failed to write output", "error", err)
+	}
+}
+
+// listPath returns the contents of remotePath if it is a directory, or its own entry if
+// it is a file.
+func listPath(ctx context.Context, client *ezshare.Client, remotePath string) ([]*ezshare.Entry, error) {
+	entry, err := client.Stat(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsDir {
+		return []*ezshare.Entry{entry}, nil
+	}
+	return client.ListDirectory(ctx, remotePath)
+}
+
+// formatLong formats an entry as a line of "ls -l" output, showing it as name.
+func formatLong(entry *ezshare.Entry, name string) string {
+	kind, size := "-", strconv.FormatInt(entry.Size, 10)
+	if entry.IsDir {
+		kind, size = "d", "-"
+	}
+	return fmt.Sprintf("%s %10s %s %s", kind, size, entry.Timestamp.Format(timeFormat), name)
+}
+
+func writeJSON(w io.Writer, entries []*ezshare.Entry) error {
+	out := make([]lsEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, lsEntry{
+			Name:      entry.Name,
+			Path:      entry.Path,
+			ShortName: entry.ShortName,
+			IsDir:     entry.IsDir,
+			Size:      entry.Size,
+			Timestamp: entry.Timestamp,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeCSV(w io.Writer, entries []*ezshare.Entry) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"path", "name", "short_name", "is_dir", "size", "timestamp"})
+	for _, entry := range entries {
+		_ = cw.Write([]string{
+			entry.Path,
+			entry.Name,
+			entry.ShortName,
+			strconv.FormatBool(entry.IsDir),
+			strconv.FormatInt(entry.Size, 10),
+			entry.Timestamp.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
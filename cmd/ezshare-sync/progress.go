@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// progressDisplay renders download progress on a single terminal line. It also serves as
+// the log output, so that log lines are not mixed into the progress line.
+type progressDisplay struct {
+	mu  sync.Mutex
+	out io.Writer
+	// lineLen is the length of the progress line currently shown, 0 if none.
+	lineLen int
+	// files and bytes count the completed transfers.
+	files int
+	bytes int64
+}
+
+func newProgressDisplay(out io.Writer) *progressDisplay {
+	return &progressDisplay{out: out}
+}
+
+// Write clears the progress line and writes p, so it can be used as the log output.
+func (d *progressDisplay) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clearLocked()
+	return d.out.Write(p)
+}
+
+// update is the ezshare progress callback.
+func (d *progressDisplay) update(ev ezshare.ProgressEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if ev.Done {
+		d.files++
+		d.bytes += ev.Transferred - ev.ResumeOffset
+		d.clearLocked()
+		return
+	}
+
+	name := ev.Entry.Path
+	if name == "" {
+		name = ev.Entry.Name
+	}
+	line := fmt.Sprintf("%s  %s", name, formatBytes(ev.Transferred))
+	if ev.Total > 0 {
+		line = fmt.Sprintf("%s/%s %3d%%", line, formatBytes(ev.Total), ev.Transferred*100/ev.Total)
+	}
+	line = fmt.Sprintf("%s  %s/s  [%d files, %s done]", line, formatBytes(int64(ev.BytesPerSecond)), d.files, formatBytes(d.bytes))
+
+	d.clearLocked()
+	_, _ = io.WriteString(d.out, line)
+	d.lineLen = len(line)
+}
+
+func (d *progressDisplay) clearLocked() {
+	if d.lineLen > 0 {
+		_, _ = fmt.Fprintf(d.out, "\r%s\r", strings.Repeat(" ", d.lineLen))
+		d.lineLen = 0
+	}
+}
+
+// formatBytes formats n using binary units, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// minRangeChunkSize is the smallest range requested when downloading with --parallel-ranges.
+const minRangeChunkSize = 256 * 1024
+
+// runSync downloads new and modified files from the card to a local directory.
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		targetDir = fs.String("target", "", "Target directory for sync (required)")
+		dryRun    = fs.Bool("dry-run", false, "Preview what would be synced without actually doing it")
+		exactSize = fs.Bool("exact-size", false, "Compare exact file sizes (one extra request per unchanged file)")
+		ranges    = fs.Int("parallel-ranges", 1, "Download large files as this many concurrent range requests")
+		progress  = fs.Bool("progress", false, "Show download progress on the terminal")
+		jobs      = fs.Int("jobs", 1, "Number of directories and files to sync concurrently")
+		adaptive  = fs.Bool("adaptive", false, "Lower concurrency while the card returns 5xx errors or times out")
+	)
+	filters := &filter{}
+	filters.register(fs)
+	cf.parse(fs, args)
+
+	var logOutput io.Writer = os.Stderr
+	var display *progressDisplay
+	if *progress {
+		display = newProgressDisplay(os.Stderr)
+		logOutput = display
+	}
+	cf.setupLogging(logOutput)
+
+	if *targetDir == "" {
+		fatal("--target flag is required")
+	}
+
+	var opts []ezshare.Option
+	if *ranges > 1 {
+		opts = append(opts, ezshare.WithParallelRanges(*ranges, minRangeChunkSize))
+	}
+	if display != nil {
+		opts = append(opts, ezshare.WithProgress(display.update))
+	}
+	lim := newLimiter(*jobs, *adaptive)
+	if *adaptive {
+		// Retried failures are the earliest sign of an overloaded card
+		policy := ezshare.NewBackoffPolicy(cf.retries)
+		policy.OnRetry = func(_ int, err error, _ time.Duration) { lim.failed(err) }
+		opts = append(opts, ezshare.WithRetryPolicy(policy))
+	}
+	client := cf.newClient(opts...)
+
+	// Cancel on Ctrl-C or SIGTERM, so that interrupted downloads are left ready to resume
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *dryRun {
+		slog.Info("dry run mode, no files will be modified")
+	}
+
+	fw, err := client.DetectFirmware(ctx)
+	if err != nil {
+		fatal("failed to detect firmware", "error", err)
+	}
+
+	slog.Info("syncing", "url", cf.baseURL, "chip", fw.ChipModel(), "target", *targetDir)
+
+	syncOpts := syncOptions{
+		dryRun:    *dryRun,
+		exactSize: *exactSize,
+		location:  cf.location,
+		filter:    filters,
+	}
+	stats := &syncStats{}
+	if err := syncDirectory(ctx, client, "/", *targetDir, syncOpts, stats, lim); err != nil {
+		fatal("sync failed", "error", err)
+	}
+
+	slog.Info("sync complete", "synced", stats.synced.Load(), "skipped", stats.skipped.Load(), "errors", stats.errors.Load())
+
+	if stats.errors.Load() > 0 {
+		os.Exit(1)
+	}
+}
+
+type syncOptions struct {
+	dryRun    bool
+	exactSize bool
+	// location is the time zone of the card's clock.
+	location *time.Location
+	// filter selects the remote paths to sync.
+	filter *filter
+}
+
+// syncStats counts the outcomes of a sync. It is updated concurrently by the jobs.
+type syncStats struct {
+	synced  atomic.Int64
+	skipped atomic.Int64
+	errors  atomic.Int64
+}
+
+// syncer syncs a directory tree, listing directories and syncing files concurrently as
+// jobs bounded by a limiter.
+type syncer struct {
+	client  *ezshare.Client
+	opts    syncOptions
+	stats   *syncStats
+	limiter *limiter
+	wg      sync.WaitGroup
+}
+
+// syncDirectory syncs the tree at remotePath into localBase. Only a failure to list
+// remotePath itself is returned; other failures are logged and counted in stats.
+func syncDirectory(ctx context.Context, client *ezshare.Client, remotePath, localBase string, opts syncOptions, stats *syncStats, lim *limiter) error {
+	s := &syncer{client: client, opts: opts, stats: stats, limiter: lim}
+
+	root, err := client.Stat(ctx, remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to list directory %s: %w", remotePath, err)
+	}
+	lim.acquire()
+	listing, err := client.ListEntry(ctx, root)
+	lim.release(err)
+	if err != nil {
+		return fmt.Errorf("failed to list directory %s: %w", remotePath, err)
+	}
+
+	s.syncEntries(ctx, listing.Entries, localBase)
+	s.wg.Wait()
+	return nil
+}
+
+// syncEntries starts a job for each entry of a directory listing, waiting for the
+// limiter before each one.
+func (s *syncer) syncEntries(ctx context.Context, entries []*ezshare.Entry, localBase string) {
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if s.opts.filter.excluded(entry.Path, entry.IsDir) {
+			// Excluded directories are not listed at all
+			slog.Debug("excluded", "path", entry.Path)
+			continue
+		}
+
+		if entry.IsDir {
+			// Local directories are created by syncFile, so that directories listed only
+			// to look for included files are not left behind empty
+			s.start(func() error {
+				listing, err := s.client.ListEntry(ctx, entry)
+				if err != nil {
+					slog.Error("failed to sync directory", "path", entry.Path, "error", err)
+					s.stats.errors.Add(1)
+					return err
+				}
+				// Start the jobs for the contents once this one has released the limiter
+				s.wg.Add(1)
+				go func() {
+					defer s.wg.Done()
+					s.syncEntries(ctx, listing.Entries, localBase)
+				}()
+				return nil
+			})
+			continue
+		}
+
+		localPath := filepath.Join(localBase, filepath.FromSlash(entry.Path))
+		s.start(func() error {
+			err := syncFile(ctx, s.client, entry, entry.Path, localPath, s.opts, s.stats)
+			if err != nil {
+				slog.Error("failed to sync file", "path", entry.Path, "error", err)
+				s.stats.errors.Add(1)
+			}
+			return err
+		})
+	}
+}
+
+// start runs job in a new goroutine once the limiter allows it.
+func (s *syncer) start(job func() error) {
+	s.limiter.acquire()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.limiter.release(job())
+	}()
+}
+
+func syncFile(ctx context.Context, client *ezshare.Client, entry *ezshare.Entry, remotePath, localPath string, opts syncOptions, stats *syncStats) error {
+	needsSync, reason := fileNeedsSync(entry, localPath, opts.location)
+
+	if !needsSync && opts.exactSize {
+		// Sizes in listings are rounded up to KB; ask the device for the exact size
+		if err := client.Head(ctx, entry); err != nil {
+			return fmt.Errorf("failed to get file metadata: %w", err)
+		}
+		needsSync, reason = fileNeedsSync(entry, localPath, opts.location)
+	}
+
+	if !needsSync {
+		stats.skipped.Add(1)
+		return nil
+	}
+
+	if opts.dryRun {
+		slog.Info("would sync", "path", remotePath, "reason", reason)
+		stats.synced.Add(1)
+		return nil
+	}
+
+	start := time.Now()
+	slog.Info("syncing file", "path", remotePath, "reason", reason)
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	tempPath := localPath + ".tmp"
+	infoPath := tempPath + ".info"
+	if !partialMatches(entry, tempPath, infoPath, opts.location) {
+		_ = os.Remove(tempPath)
+		// Record which version of the file is being downloaded before any of it is written,
+		// so the next run can resume it even if this one is killed
+		if err := writePartialInfo(entry, infoPath); err != nil {
+			return fmt.Errorf("failed to save download state: %w", err)
+		}
+	}
+	if err := client.DownloadFile(ctx, entry, tempPath); err != nil {
+		var statusErr *ezshare.StatusError
+		if errors.As(err, &statusErr) && statusErr.Code < 500 {
+			// The device refused the file, such as one deleted since it was listed
+			removePartial(tempPath, infoPath)
+		} else if _, statErr := os.Stat(tempPath); os.IsNotExist(statErr) {
+			// Nothing was kept to resume
+			_ = os.Remove(infoPath)
+		}
+		// Otherwise keep what was received, so the next run can resume it
+		return fmt.Errorf("failed to download: %w", err)
+	}
+
+	if err := os.Chtimes(tempPath, entry.Timestamp, entry.Timestamp); err != nil {
+		removePartial(tempPath, infoPath)
+		return fmt.Errorf("failed to set timestamp: %w", err)
+	}
+
+	if err := os.Rename(tempPath, localPath); err != nil {
+		removePartial(tempPath, infoPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	_ = os.Remove(infoPath)
+
+	slog.Debug("synced file", "path", remotePath, "duration", time.Since(start))
+	stats.synced.Add(1)
+	return nil
+}
+
+// partialInfo identifies the version of a remote file that a partial download belongs to.
+// It is saved as JSON next to the partial file.
+type partialInfo struct {
+	Size      int64     `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func writePartialInfo(entry *ezshare.Entry, infoPath string) error {
+	data, err := json.Marshal(partialInfo{Size: entry.Size, Timestamp: entry.Timestamp})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(infoPath, data, 0644)
+}
+
+func removePartial(tempPath, infoPath string) {
+	_ = os.Remove(tempPath)
+	_ = os.Remove(infoPath)
+}
+
+// partialMatches reports whether tempPath holds a partial download left behind by an earlier
+// run for the same version of the remote file, as recorded in infoPath. A partial file of a
+// file that changed since is discarded.
+func partialMatches(entry *ezshare.Entry, tempPath, infoPath string, location *time.Location) bool {
+	info, err := os.Stat(tempPath)
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return false
+	}
+	var saved partialInfo
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return false
+	}
+
+	expectedSize := entry.Size
+	if entry.ExactSize > 0 {
+		expectedSize = entry.ExactSize
+	}
+	return info.Size() < expectedSize && saved.Size == entry.Size &&
+		sameTimestamp(saved.Timestamp, entry.Timestamp, location)
+}
+
+func fileNeedsSync(entry *ezshare.Entry, localPath string, location *time.Location) (bool, string) {
+	info, err := os.Stat(localPath)
+	if os.IsNotExist(err) {
+		return true, "new file"
+	}
+	if err != nil {
+		return true, fmt.Sprintf("stat error: %v", err)
+	}
+
+	if entry.ExactSize > 0 && info.Size() != entry.ExactSize {
+		return true, "size mismatch"
+	}
+
+	// The API returns sizes rounded up to KB (base-2: 1024 bytes)
+	// Check if local file rounds to the same KB value as remote
+	localSizeKB := (info.Size() + 1023) / 1024
+	remoteSizeKB := (entry.Size + 1023) / 1024
+	if localSizeKB != remoteSizeKB {
+		return true, "size mismatch"
+	}
+
+	if !sameTimestamp(info.ModTime(), entry.Timestamp, location) {
+		return true, "timestamp mismatch"
+	}
+
+	return false, ""
+}
+
+// sameTimestamp reports whether a local modification time matches a timestamp from the
+// card, allowing for FAT's 2-second resolution. The times are compared as wall-clock times
+// in the card's time zone, so a file whose time was set with the other offset of a
+// repeated DST hour still matches.
+func sameTimestamp(local, remote time.Time, location *time.Location) bool {
+	diff := wallClock(local.In(location)).Sub(wallClock(remote.In(location)))
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= 10*time.Second
+}
+
+// wallClock returns the wall-clock time of t in its location, as a UTC time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
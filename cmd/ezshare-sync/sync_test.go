@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+	"github.com/haimgel/ezshare-sync/ezshare/ezsharetest"
+)
+
+// setupTestCard serves files from a fake card and returns the server and a client for it.
+func setupTestCard(t *testing.T, files map[string]string) (*ezsharetest.Server, *ezshare.Client) {
+	t.Helper()
+	root := t.TempDir()
+	modTime := time.Date(2026, 1, 4, 23, 41, 40, 0, time.UTC)
+	for name, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.Chtimes(fullPath, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set timestamp: %v", err)
+		}
+	}
+
+	server := ezsharetest.NewServer(root)
+	server.Handler.Location = time.UTC
+	t.Cleanup(server.Close)
+
+	client, err := ezshare.NewClient(server.URL, ezshare.WithHTTPClient(server.Client()), ezshare.WithDeviceLocation(time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return server, client
+}
+
+// cardFiles is a card with therapy data alongside camera pictures and the card's own files.
+var cardFiles = map[string]string{
+	"STR.edf":            "str",
+	"Identification.tgt": "id",
+	"DATALOG/20260104/20260104_234156_BRP.edf": "brp",
+	"DATALOG/20260105/20260105_230000_EVE.edf": "eve",
+	"DCIM/100MEDIA/IMG0001.JPG":                "jpg",
+	"System Volume Information/WPSettings.dat": "wp",
+}
+
+func TestSyncDirectory_Filter(t *testing.T) {
+	_, client := setupTestCard(t, cardFiles)
+	filters := &filter{}
+	for _, rule := range []string{"+ DATALOG/**", "+ STR.edf", "- *"} {
+		if err := filters.addRule(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	target := t.TempDir()
+	opts := syncOptions{location: time.UTC, filter: filters}
+	stats := &syncStats{}
+	if err := syncDirectory(context.Background(), client, "/", target, opts, stats, newLimiter(1, false)); err != nil {
+		t.Fatalf("syncDirectory failed: %v", err)
+	}
+
+	if stats.synced.Load() != 3 || stats.errors.Load() != 0 {
+		t.Errorf("synced %d, errors %d; want 3 synced", stats.synced.Load(), stats.errors.Load())
+	}
+	for _, name := range []string{"STR.edf", "DATALOG/20260104/20260104_234156_BRP.edf", "DATALOG/20260105/20260105_230000_EVE.edf"} {
+		if _, err := os.Stat(filepath.Join(target, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %s to be synced: %v", name, err)
+		}
+	}
+	// Directories listed only to look for included files are not created
+	for _, name := range []string{"Identification.tgt", "DCIM", "System Volume Information"} {
+		if _, err := os.Stat(filepath.Join(target, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be created", name)
+		}
+	}
+}
+
+func TestSyncFile_RemovesPartialOnPermanentError(t *testing.T) {
+	_, client := setupTestCard(t, cardFiles)
+	entry, err := client.Stat(context.Background(), "/STR.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	// The file was deleted from the card since it was listed
+	entry.URL += "-deleted"
+
+	localPath := filepath.Join(t.TempDir(), "STR.edf")
+	tempPath, infoPath := localPath+".tmp", localPath+".tmp.info"
+	if err := os.WriteFile(tempPath, []byte("s"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePartialInfo(entry, infoPath); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := syncOptions{location: time.UTC}
+	if err := syncFile(context.Background(), client, entry, entry.Path, localPath, opts, &syncStats{}); err == nil {
+		t.Fatal("expected the download to fail")
+	}
+	for _, name := range []string{tempPath, infoPath} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", filepath.Base(name))
+		}
+	}
+}
+
+func TestSyncDirectory_AdaptiveJobs(t *testing.T) {
+	files := map[string]string{}
+	for day := range 4 {
+		for n := range 6 {
+			files[fmt.Sprintf("DATALOG/2026010%d/F%d.edf", day+1, n)] = strings.Repeat("x", 2048+n)
+		}
+	}
+	card, _ := setupTestCard(t, files)
+
+	// Refuse the first request for each file with a 503, and track concurrent downloads
+	var (
+		mu      sync.Mutex
+		refused = map[string]bool{}
+	)
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			mu.Lock()
+			first := !refused[r.URL.RawQuery]
+			refused[r.URL.RawQuery] = true
+			mu.Unlock()
+			if first {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	lim := newLimiter(4, true)
+	policy := ezshare.NewBackoffPolicy(3)
+	policy.InitialDelay = time.Millisecond
+	policy.OnRetry = func(_ int, err error, _ time.Duration) { lim.failed(err) }
+	client, err := ezshare.NewClient(server.URL, ezshare.WithRetryPolicy(policy), ezshare.WithDeviceLocation(time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	target := t.TempDir()
+	stats := &syncStats{}
+	if err := syncDirectory(context.Background(), client, "/", target, syncOptions{location: time.UTC}, stats, lim); err != nil {
+		t.Fatalf("syncDirectory failed: %v", err)
+	}
+
+	if stats.synced.Load() != int64(len(files)) || stats.errors.Load() != 0 {
+		t.Errorf("synced %d, errors %d; want %d synced", stats.synced.Load(), stats.errors.Load(), len(files))
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(target, filepath.FromSlash(name)))
+		if err != nil || string(data) != content {
+			t.Errorf("%s was not synced correctly: %v", name, err)
+		}
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("peak concurrent downloads = %d, want at most 4", p)
+	}
+	if lim.lastShrink.IsZero() {
+		t.Error("expected the 503 responses to reduce the limit")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+)
+
+// treeNode is an entry of the card's directory tree, loaded by loadTree.
+type treeNode struct {
+	entry    *ezshare.Entry
+	children []*treeNode
+	// err is the error listing the directory, if any.
+	err error
+}
+
+// loadTree walks the directory tree rooted at root and returns it. Directories that
+// can't be listed are logged and kept with their error; the walk carries on.
+func loadTree(ctx context.Context, client *ezshare.Client, root string) (*treeNode, error) {
+	var top *treeNode
+	nodes := map[string]*treeNode{}
+	err := client.Walk(ctx, root, func(p string, entry *ezshare.Entry, err error) error {
+		if err != nil && entry == nil {
+			// The root itself wasn't found
+			return err
+		}
+		if err != nil {
+			slog.Error("failed to list directory", "path", p, "error", err)
+			nodes[p].err = err
+			return nil
+		}
+		node := &treeNode{entry: entry}
+		nodes[p] = node
+		if top == nil {
+			top = node
+		} else if parent := nodes[path.Dir(p)]; parent != nil {
+			parent.children = append(parent.children, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return top, nil
+}
+
+// size returns the total size of the files under the node.
+func (n *treeNode) size() int64 {
+	if !n.entry.IsDir {
+		return n.entry.Size
+	}
+	var total int64
+	for _, child := range n.children {
+		total += child.size()
+	}
+	return total
+}
+
+// runTree prints the directory tree of the card.
+func runTree(args []string) {
+	fs := flag.NewFlagSet("tree", flag.ExitOnError)
+	var cf clientFlags
+	cf.register(fs)
+	var (
+		dirsOnly = fs.Bool("d", false, "List directories only")
+		sizes    = fs.Bool("s", false, "Show file sizes")
+	)
+	root := "/"
+	if rest := cf.parse(fs, args); len(rest) > 1 {
+		fatal("tree takes at most one path")
+	} else if len(rest) == 1 {
+		root = rest[0]
+	}
+
+	cf.setupLogging(os.Stderr)
+	client := cf.newClient()
+
+	tree, err := loadTree(context.Background(), client, root)
+	if err != nil {
+		fatal("failed to list", "path", root, "error", err)
+	}
+
+	fmt.Println(tree.entry.Path)
+	if !printTree(tree, "", *dirsOnly, *sizes) {
+		os.Exit(1)
+	}
+}
+
+// printTree prints the children of node, and reports whether all directories below it
+// were listed.
+func printTree(node *treeNode, prefix string, dirsOnly, sizes bool) bool {
+	ok := node.err == nil
+	var children []*treeNode
+	for _, child := range node.children {
+		if !dirsOnly || child.entry.IsDir {
+			children = append(children, child)
+		}
+	}
+
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := child.entry.Name
+		if sizes && !child.entry.IsDir {
+			name = fmt.Sprintf("%s (%s)", name, formatBytes(child.entry.Size))
+		}
+		if child.err != nil {
+			name += " [error listing directory]"
+		}
+		fmt.Println(prefix + branch + name)
+		ok = printTree(child, prefix+indent, dirsOnly, sizes) && ok
+	}
+	return ok
+}
@@ -2,12 +2,14 @@ package ezshare
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/proxy"
@@ -27,6 +29,21 @@ type Client struct {
 	maxRetries int
 	userAgent  string
 	logger     Logger
+	slog       *slog.Logger
+
+	retryPolicy RetryPolicy
+
+	parallelRanges int
+	minChunkSize   int64
+
+	progress func(ProgressEvent)
+
+	lenientListings bool
+	location        *time.Location
+	rewriteHrefs    bool
+
+	firmwareMu sync.Mutex
+	firmware   Firmware
 }
 
 // NewClient creates a new EZ-Share client with the given base URL and options.
@@ -41,16 +58,22 @@ func NewClient(baseURL string, opts ...Option) (*Client, error) {
 	}
 
 	c := &Client{
-		baseURL:    parsedURL,
-		timeout:    10 * time.Minute,
-		maxRetries: 3,
-		userAgent:  "ezshare-go/1.0",
+		baseURL:      parsedURL,
+		timeout:      10 * time.Minute,
+		maxRetries:   3,
+		userAgent:    "ezshare-go/1.0",
+		location:     time.Local,
+		rewriteHrefs: true,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	if c.retryPolicy == nil {
+		c.retryPolicy = NewBackoffPolicy(c.maxRetries)
+	}
+
 	if c.httpClient == nil {
 		transport := &http.Transport{
 			DialContext: (&net.Dialer{
@@ -85,79 +108,78 @@ func NewClient(baseURL string, opts ...Option) (*Client, error) {
 }
 
 func (c *Client) buildURL(path, paramName, paramValue string) string {
-	u := *c.baseURL
+	return buildDeviceURL(c.baseURL, path, paramName, paramValue)
+}
+
+// buildDeviceURL builds a device URL with a single query parameter. The value is
+// converted to the device encoding before it is escaped.
+func buildDeviceURL(baseURL *url.URL, path, paramName, paramValue string) string {
+	u := *baseURL
 	u.Path = path
-	q := u.Query()
-	q.Set(paramName, paramValue)
-	u.RawQuery = q.Encode()
+	u.RawQuery = url.QueryEscape(paramName) + "=" + escapeQueryValue(paramValue)
 	return u.String()
 }
 
-func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+// resolveHref resolves an href found in the listing at base. Unless disabled with
+// WithHrefRewriting, the scheme and host are replaced by those of the client's base URL,
+// because the card links to itself as 192.168.4.1 however it is reached.
+func (c *Client) resolveHref(base *url.URL, href string) string {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	u := base.ResolveReference(ref)
+	if c.rewriteHrefs {
+		u.Scheme = c.baseURL.Scheme
+		u.Host = c.baseURL.Host
+		u.User = c.baseURL.User
+	}
+	return u.String()
+}
+
+// doRequest sends req with the client's settings. op names the kind of request for
+// StatusError, and 5xx responses are returned as a StatusError.
+func (c *Client) doRequest(ctx context.Context, op string, req *http.Request) (*http.Response, error) {
 	reqWithCtx := req.Clone(ctx)
 	if c.userAgent != "" {
 		reqWithCtx.Header.Set("User-Agent", c.userAgent)
 	}
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(reqWithCtx)
 	if err != nil {
+		c.logAttrs(ctx, slog.LevelDebug, "request failed",
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err))
 		return nil, err
 	}
+	if c.slog != nil {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.Int("status", resp.StatusCode),
+			slog.Int64("bytes", resp.ContentLength),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if byteRange := req.Header.Get("Range"); byteRange != "" {
+			attrs = append(attrs, slog.String("range", byteRange))
+		}
+		c.logAttrs(ctx, slog.LevelDebug, "request", attrs...)
+	}
 
 	if resp.StatusCode >= 500 && resp.StatusCode < 600 {
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("%w: HTTP %d", ErrServerError, resp.StatusCode)
+		return nil, statusError(op, resp)
 	}
 
 	return resp, nil
 }
 
-func (c *Client) retryOperation(ctx context.Context, operation func() error) error {
-	var lastErr error
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := time.Duration(1<<uint(attempt-1)) * 500 * time.Millisecond
-			if c.logger != nil {
-				c.logger.Printf("Retrying operation (attempt %d/%d) after error: %v (waiting %v)", attempt, c.maxRetries, lastErr, backoff)
-			}
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
-
-		err := operation()
-		if err == nil {
-			return nil
-		}
-
-		if !isRetriableError(err) {
-			return err
-		}
-
-		lastErr = err
-	}
-	return fmt.Errorf("operation failed after %d retries: %w", c.maxRetries, lastErr)
-}
-
-func isRetriableError(err error) bool {
-	if errors.Is(err, context.DeadlineExceeded) {
-		return true
-	}
-	if errors.Is(err, context.Canceled) {
-		return false
-	}
-	if errors.Is(err, ErrServerError) {
-		return true
-	}
-
-	var netErr net.Error
-	if errors.As(err, &netErr) && netErr.Timeout() {
-		return true
-	}
-
-	return false
+// cleanPath normalizes a Unix-style device path to an absolute, slash-separated form.
+func cleanPath(p string) string {
+	return path.Clean("/" + p)
 }
 
 func convertUnixPathToAPI(path string) string {
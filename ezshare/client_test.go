@@ -1,9 +1,40 @@
 package ezshare
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare/ezsharetest"
 )
 
+// setupTestCard starts a fake card with the given files (keyed by Unix-style path) and
+// returns a client connected to it.
+func setupTestCard(t *testing.T, files map[string]string) (*ezsharetest.Server, *Client) {
+	t.Helper()
+	root := t.TempDir()
+	modTime := time.Date(2026, 1, 4, 23, 41, 40, 0, time.UTC)
+	for name, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.Chtimes(fullPath, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set timestamp: %v", err)
+		}
+	}
+
+	server := ezsharetest.NewServer(root)
+	server.Handler.Location = time.UTC
+	t.Cleanup(server.Close)
+
+	return server, createTestClient(t, server.URL, WithHTTPClient(server.Client()), WithDeviceLocation(time.UTC))
+}
+
 func TestConvertUnixPathToAPI(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -0,0 +1,179 @@
+package ezshare
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultDiscoveryHosts are the addresses tried first by Discover: the card's hostname,
+// and its address in access point mode.
+var DefaultDiscoveryHosts = []string{"ezshare.card", "192.168.4.1"}
+
+// DiscoverOptions configures Discover. The zero value tries DefaultDiscoveryHosts only.
+type DiscoverOptions struct {
+	// Hosts are the addresses to try, as "host" or "host:port". Defaults to DefaultDiscoveryHosts.
+	Hosts []string
+	// ScanSubnets also probes every address in the /24 subnets of the local IPv4 interfaces.
+	ScanSubnets bool
+	// Timeout bounds each probe. Defaults to 2 seconds.
+	Timeout time.Duration
+	// Concurrency is the maximum number of simultaneous probes. Defaults to 64.
+	Concurrency int
+	// ClientOptions configure the clients used for the probes, e.g. WithSOCKS5Proxy.
+	// Probes are never retried.
+	ClientOptions []Option
+}
+
+// Device is a card found by Discover.
+type Device struct {
+	// Address is the host (and port, if not 80) the card answered on, usable as a base URL.
+	Address string
+	// Latency is the round-trip time of the version request.
+	Latency time.Duration
+	Version *Version
+}
+
+// Discover looks for EZ-Share cards by requesting the firmware version from each candidate
+// address. Devices are returned in the order the addresses were tried: opts.Hosts first,
+// then the scanned subnets. An address that does not answer in time is skipped, so an
+// empty result with a nil error means no card was found.
+func Discover(ctx context.Context, opts DiscoverOptions) ([]*Device, error) {
+	hosts := opts.Hosts
+	if len(hosts) == 0 {
+		hosts = DefaultDiscoveryHosts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 64
+	}
+
+	// Share one transport between all probes
+	template, err := NewClient(hosts[0], opts.ClientOptions...)
+	if err != nil {
+		return nil, err
+	}
+	probeOpts := append(append([]Option(nil), opts.ClientOptions...), WithHTTPClient(template.httpClient))
+
+	devices := probeHosts(ctx, hosts, opts.Timeout, opts.Concurrency, probeOpts)
+	if !opts.ScanSubnets {
+		return devices, ctx.Err()
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return devices, err
+	}
+	// Don't report a card found by name again under its IP address
+	known := make(map[string]bool)
+	for _, device := range devices {
+		known[device.Address] = true
+		host, _, err := net.SplitHostPort(device.Address)
+		if err != nil {
+			host = device.Address
+		}
+		if ips, err := net.DefaultResolver.LookupHost(ctx, host); err == nil {
+			for _, ip := range ips {
+				known[ip] = true
+			}
+		}
+	}
+	var scan []string
+	for _, host := range subnetHosts(addrs) {
+		if !known[host] {
+			scan = append(scan, host)
+		}
+	}
+
+	return append(devices, probeHosts(ctx, scan, opts.Timeout, opts.Concurrency, probeOpts)...), ctx.Err()
+}
+
+// probeHosts probes hosts concurrently, and returns the devices found in the order of hosts.
+func probeHosts(ctx context.Context, hosts []string, timeout time.Duration, concurrency int, opts []Option) []*Device {
+	results := make([]*Device, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = probeHost(ctx, host, timeout, opts)
+		}()
+	}
+	wg.Wait()
+
+	var devices []*Device
+	for _, device := range results {
+		if device != nil {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// probeHost pings host, returning nil if it is not a card.
+func probeHost(ctx context.Context, host string, timeout time.Duration, opts []Option) *Device {
+	client, err := NewClient(host, opts...)
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ping, err := client.Ping(ctx)
+	if err != nil {
+		return nil
+	}
+	return &Device{
+		Address: host,
+		Latency: ping.Latency,
+		Version: ping.Version,
+	}
+}
+
+// subnetHosts returns the addresses in the /24 subnets of the given IPv4 interface
+// addresses, excluding the interface addresses themselves and loopback.
+func subnetHosts(addrs []net.Addr) []string {
+	own := make(map[string]bool)
+	var subnets []net.IP
+	seen := make(map[string]bool)
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipNet.IP.To4()
+		if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		own[ip.String()] = true
+		subnet := ip.Mask(net.CIDRMask(24, 32))
+		if !seen[subnet.String()] {
+			seen[subnet.String()] = true
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	var hosts []string
+	for _, subnet := range subnets {
+		for i := 1; i < 255; i++ {
+			host := net.IPv4(subnet[0], subnet[1], subnet[2], byte(i)).String()
+			if !own[host] {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,67 @@
+package ezshare
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDiscover_FindsCard(t *testing.T) {
+	server, _ := setupTestCard(t, map[string]string{"STR.edf": "data"})
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedAddr := listener.Addr().String()
+	_ = listener.Close()
+
+	devices, err := Discover(context.Background(), DiscoverOptions{
+		Hosts:   []string{closedAddr, serverURL.Host},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 device, got %d", len(devices))
+	}
+	if devices[0].Address != serverURL.Host {
+		t.Errorf("expected address %s, got %s", serverURL.Host, devices[0].Address)
+	}
+	if devices[0].Version == nil || devices[0].Version.ChipModel != "LZ1801EDPG" {
+		t.Errorf("unexpected version: %+v", devices[0].Version)
+	}
+	if devices[0].Latency <= 0 {
+		t.Errorf("expected a positive latency, got %v", devices[0].Latency)
+	}
+}
+
+func TestSubnetHosts(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.IPv4(127, 0, 0, 1), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.IPv4(192, 168, 4, 2), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.IPv4(192, 168, 4, 3), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+	}
+
+	hosts := subnetHosts(addrs)
+	if len(hosts) != 252 {
+		t.Fatalf("expected 252 hosts, got %d", len(hosts))
+	}
+	if hosts[0] != "192.168.4.1" || hosts[len(hosts)-1] != "192.168.4.254" {
+		t.Errorf("unexpected range %s..%s", hosts[0], hosts[len(hosts)-1])
+	}
+	for _, host := range hosts {
+		if host == "192.168.4.2" || host == "192.168.4.3" {
+			t.Errorf("own address %s should be skipped", host)
+		}
+	}
+}
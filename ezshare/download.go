@@ -2,65 +2,194 @@ package ezshare
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"os"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 )
 
-const minResumableSize = 100 * 1024 // 100KB
+// errRangeNotSatisfiable is returned by range requests that start at or past the end of the file.
+var errRangeNotSatisfiable = errors.New("requested range not satisfiable")
 
 // DownloadFile downloads a file from the device and saves it to the specified destination path.
+// If destPath already holds a shorter, partial copy of the file, the download resumes from its end.
+// If the connection drops mid-transfer, the download reconnects from the last byte received.
+// The number of bytes received is checked against the Content-Length reported by the device.
+// If parallel ranges are enabled with WithParallelRanges, large files are downloaded as
+// several concurrent range requests, unless there is a partial copy to resume.
 func (c *Client) DownloadFile(ctx context.Context, entry *Entry, destPath string) error {
+	start := time.Now()
+	err := c.downloadFile(ctx, entry, destPath)
+	if err == nil {
+		var size int64
+		if info, statErr := os.Stat(destPath); statErr == nil {
+			size = info.Size()
+		}
+		c.logAttrs(ctx, slog.LevelDebug, "download complete",
+			entryAttr(entry),
+			slog.Int64("bytes", size),
+			slog.Duration("duration", time.Since(start)))
+	}
+	return opError("download", entryPath(entry), err)
+}
+
+func (c *Client) downloadFile(ctx context.Context, entry *Entry, destPath string) error {
+	// A parallel download recreates the file, so a partial copy is resumed as one stream
+	if _, resume := validatePartialFile(destPath, expectedFileSize(entry)); c.useParallelRanges(entry) && !resume {
+		err := c.downloadParallel(ctx, entry, destPath)
+		if !errors.Is(err, errRangesNotSupported) {
+			return err
+		}
+		if c.logger != nil {
+			c.logger.Printf("Device ignored range requests, falling back to a single stream: %s", entry.Name)
+		}
+		c.logAttrs(ctx, slog.LevelInfo, "device ignored range requests, falling back to a single stream",
+			entryAttr(entry))
+	}
+
 	return c.retryOperation(ctx, func() error {
 		return c.downloadFileAttempt(ctx, entry, destPath)
 	})
 }
 
 // DownloadFileByPath downloads a file by its Unix-style path. This is a convenience method
-// that constructs the download URL directly. For files obtained from ListDirectory, use DownloadFile instead.
+// that constructs the download URL directly. Path components may be given as long names or
+// as 8.3 names; long names are resolved through the parent directory listing.
+// For files obtained from ListDirectory, use DownloadFile instead.
 func (c *Client) DownloadFileByPath(ctx context.Context, filePath, destPath string) error {
-	apiPath := convertUnixPathToAPI(filePath)
-	downloadURL := c.buildURL("/download", "file", apiPath)
+	filePath = cleanPath(filePath)
+	if needsShortNames(filePath) {
+		entry, err := c.Stat(ctx, filePath)
+		if err != nil {
+			return err
+		}
+		return c.DownloadFile(ctx, entry, destPath)
+	}
+
+	fw, err := c.DetectFirmware(ctx)
+	if err != nil {
+		return opError("download", filePath, err)
+	}
+	apiPath := fw.APIPath(filePath)
+	downloadURL := fw.DownloadURL(c.baseURL, apiPath)
 
 	entry := &Entry{
-		Name: filePath,
-		URL:  downloadURL,
+		Name:       path.Base(filePath),
+		Path:       filePath,
+		URL:        downloadURL,
+		ShortName:  strings.ToUpper(path.Base(filePath)),
+		RemotePath: apiPath,
 	}
 
 	return c.DownloadFile(ctx, entry, destPath)
 }
 
 // GetFile opens a file from the device and returns a ReadCloser for streaming the contents.
+// Opening the file is retried according to the retry policy, and if the connection drops
+// while reading, the stream transparently reconnects from the last byte read.
 func (c *Client) GetFile(ctx context.Context, entry *Entry) (io.ReadCloser, error) {
+	var resp *http.Response
+	err := c.retryOperation(ctx, func() error {
+		var err error
+		resp, err = c.getFileResponse(ctx, entry)
+		return err
+	})
+	if err != nil {
+		return nil, opError("download", entryPath(entry), err)
+	}
+	return c.newResumingReader(ctx, entry, resp, 0), nil
+}
+
+// Head fetches the exact size, ETag, content type, and server-side file name of a file
+// from the download endpoint, and stores them in entry. The device does not reliably
+// support HEAD requests, so this issues a GET for the first byte of the file.
+func (c *Client) Head(ctx context.Context, entry *Entry) error {
+	err := c.retryOperation(ctx, func() error {
+		return c.headAttempt(ctx, entry)
+	})
+	return opError("head", entryPath(entry), err)
+}
+
+func (c *Client) headAttempt(ctx context.Context, entry *Entry) error {
+	req, err := http.NewRequest("GET", entry.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := c.doRequest(ctx, "head", req)
+	if err != nil {
+		return fmt.Errorf("metadata request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var exactSize int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+		// "bytes 0-0/21586", or "bytes */0" for an empty file
+		exactSize, err = parseContentRangeTotal(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+	case http.StatusOK:
+		if resp.ContentLength < 0 {
+			return fmt.Errorf("%w: response has no Content-Length", ErrInvalidResponse)
+		}
+		exactSize = resp.ContentLength
+	default:
+		return statusError("head", resp)
+	}
+
+	entry.ExactSize = exactSize
+	entry.ETag = resp.Header.Get("ETag")
+	entry.ContentType = resp.Header.Get("Content-Type")
+	entry.ServerFilename = ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		entry.ServerFilename = params["filename"]
+	}
+	return nil
+}
+
+func parseContentRangeTotal(contentRange string) (int64, error) {
+	i := strings.LastIndex(contentRange, "/")
+	if !strings.HasPrefix(contentRange, "bytes ") || i < 0 {
+		return 0, &ParseError{Line: contentRange, Reason: "malformed Content-Range"}
+	}
+	total, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return 0, &ParseError{Line: contentRange, Reason: "malformed Content-Range", Err: err}
+	}
+	return total, nil
+}
+
+func (c *Client) getFileResponse(ctx context.Context, entry *Entry) (*http.Response, error) {
 	req, err := http.NewRequest("GET", entry.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, req)
+	resp, err := c.doRequest(ctx, "download", req)
 	if err != nil {
 		return nil, fmt.Errorf("download request failed: %w", err)
 	}
 
-	if resp.StatusCode == 404 {
-		_ = resp.Body.Close()
-		return nil, fmt.Errorf("%w: %s", ErrNotFound, entry.Name)
-	}
 	if resp.StatusCode != 200 {
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, statusError("download", resp)
 	}
-	return resp.Body, nil
+	return resp, nil
 }
 
 func (c *Client) downloadFileAttempt(ctx context.Context, entry *Entry, destPath string) (err error) {
-	if entry.Size < minResumableSize {
-		return c.downloadFull(ctx, entry, destPath)
-	}
-
-	partialSize, shouldResume := validatePartialFile(destPath, entry.Size)
+	expectedSize := expectedFileSize(entry)
+	partialSize, shouldResume := validatePartialFile(destPath, expectedSize)
 	if !shouldResume {
 		_ = os.Remove(destPath)
 		return c.downloadFull(ctx, entry, destPath)
@@ -68,43 +197,60 @@ func (c *Client) downloadFileAttempt(ctx context.Context, entry *Entry, destPath
 
 	if c.logger != nil {
 		c.logger.Printf("Resuming download from byte %d/%d (%.1f%% complete): %s",
-			partialSize, entry.Size, float64(partialSize)/float64(entry.Size)*100, entry.Name)
+			partialSize, expectedSize, float64(partialSize)/float64(expectedSize)*100, entry.Name)
 	}
+	c.logAttrs(ctx, slog.LevelInfo, "resuming partial download",
+		entryAttr(entry),
+		slog.Int64("bytes", partialSize),
+		slog.Int64("total", expectedSize))
 
 	return c.downloadResume(ctx, entry, destPath, partialSize)
 }
 
 func (c *Client) downloadFull(ctx context.Context, entry *Entry, destPath string) error {
-	reader, err := c.GetFile(ctx, entry)
+	resp, err := c.getFileResponse(ctx, entry)
 	if err != nil {
 		return err
 	}
 
 	out, err := os.Create(destPath)
 	if err != nil {
-		_ = reader.Close()
+		_ = resp.Body.Close()
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 
-	return c.downloadToFile(reader, out)
+	return c.downloadToFile(c.newResumingReader(ctx, entry, resp, 0), out, resp.ContentLength)
 }
 
 func (c *Client) downloadResume(ctx context.Context, entry *Entry, destPath string, partialSize int64) error {
-	reader, err := c.getFileWithRange(ctx, entry, partialSize)
+	resp, err := c.rangeRequest(ctx, entry, partialSize)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		// The partial file is not shorter than the remote one, so it can't be a prefix of it
+		_ = os.Remove(destPath)
+		return c.downloadFull(ctx, entry, destPath)
+	}
 	if err != nil {
 		return err
 	}
 
-	out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_APPEND, 0644)
+	flags := os.O_WRONLY | os.O_APPEND
+	if resp.StatusCode == http.StatusOK {
+		// The device ignored the Range header and is sending the whole file.
+		flags = os.O_WRONLY | os.O_TRUNC
+		partialSize = 0
+	}
+	out, err := os.OpenFile(destPath, flags, 0644)
 	if err != nil {
-		_ = reader.Close()
+		_ = resp.Body.Close()
 		return fmt.Errorf("failed to open file for append: %w", err)
 	}
 
-	return c.downloadToFile(reader, out)
+	return c.downloadToFile(c.newResumingReader(ctx, entry, resp, partialSize), out, resp.ContentLength)
 }
 
-func (c *Client) downloadToFile(reader io.ReadCloser, out *os.File) (err error) {
+// downloadToFile copies reader to out, and verifies that expectedBytes were written
+// (unless expectedBytes is negative, meaning the length is unknown).
+func (c *Client) downloadToFile(reader io.ReadCloser, out *os.File, expectedBytes int64) (err error) {
 	defer func() {
 		if closeErr := reader.Close(); closeErr != nil && err == nil {
 			err = fmt.Errorf("failed to close reader: %w", closeErr)
@@ -116,13 +262,27 @@ func (c *Client) downloadToFile(reader io.ReadCloser, out *os.File) (err error)
 		}
 	}()
 
-	if _, err = io.Copy(out, reader); err != nil {
+	written, err := io.Copy(out, reader)
+	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	if expectedBytes >= 0 && written != expectedBytes {
+		return fmt.Errorf("%w: received %d bytes, expected %d", ErrInvalidResponse, written, expectedBytes)
+	}
 	return nil
 }
 
 func (c *Client) getFileWithRange(ctx context.Context, entry *Entry, byteOffset int64) (io.ReadCloser, error) {
+	resp, err := c.rangeRequest(ctx, entry, byteOffset)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// rangeRequest requests the file starting at byteOffset. The response is either a
+// validated 206, or a 200 if the device ignored the Range header.
+func (c *Client) rangeRequest(ctx context.Context, entry *Entry, byteOffset int64) (*http.Response, error) {
 	req, err := http.NewRequest("GET", entry.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -130,7 +290,7 @@ func (c *Client) getFileWithRange(ctx context.Context, entry *Entry, byteOffset
 
 	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", byteOffset))
 
-	resp, err := c.doRequest(ctx, req)
+	resp, err := c.doRequest(ctx, "download", req)
 	if err != nil {
 		return nil, fmt.Errorf("range request failed: %w", err)
 	}
@@ -139,29 +299,38 @@ func (c *Client) getFileWithRange(ctx context.Context, entry *Entry, byteOffset
 		contentRange := resp.Header.Get("Content-Range")
 		if contentRange == "" {
 			_ = resp.Body.Close()
-			return nil, fmt.Errorf("206 response missing Content-Range header")
+			return nil, fmt.Errorf("%w: 206 response missing Content-Range header", ErrInvalidResponse)
 		}
 
 		expectedPrefix := fmt.Sprintf("bytes %d-", byteOffset)
 		if !strings.HasPrefix(contentRange, expectedPrefix) {
 			_ = resp.Body.Close()
-			return nil, fmt.Errorf("unexpected Content-Range: %s (expected start at %d)", contentRange, byteOffset)
+			return nil, fmt.Errorf("%w: unexpected Content-Range %q (expected start at %d)", ErrInvalidResponse, contentRange, byteOffset)
 		}
 
-		return resp.Body, nil
+		return resp, nil
 	}
 
 	if resp.StatusCode == 200 {
-		return resp.Body, nil
+		return resp, nil
 	}
 
-	if resp.StatusCode == 404 {
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("%w: %s", ErrNotFound, entry.Name)
+		return nil, fmt.Errorf("%w: offset %d", errRangeNotSatisfiable, byteOffset)
 	}
 
 	_ = resp.Body.Close()
-	return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return nil, statusError("download", resp)
+}
+
+// expectedFileSize returns the exact size of a file if it is known, and its listing size
+// otherwise.
+func expectedFileSize(entry *Entry) int64 {
+	if entry.ExactSize > 0 {
+		return entry.ExactSize
+	}
+	return entry.Size
 }
 
 func validatePartialFile(destPath string, expectedSize int64) (partialSize int64, shouldResume bool) {
@@ -2,6 +2,7 @@ package ezshare
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestServer(t *testing.T, content string) (*httptest.Server, *Entry) {
@@ -100,19 +102,29 @@ func TestDownloadFile_SmallFile_NoResume(t *testing.T) {
 	downloadAndVerify(t, client, entry, destPath, content)
 }
 
-func TestDownloadFile_BelowThreshold_NoResume(t *testing.T) {
-	content := strings.Repeat("x", 90*1024)
-	server, entry := setupTestServer(t, content)
+func TestDownloadFile_SmallPartial_ResumesFromPartial(t *testing.T) {
+	content := "Small file content that was cut short"
+	var rangeRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests++
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
 	defer server.Close()
 
+	entry := &Entry{Name: "small.txt", URL: server.URL + "/download?file=small.txt", Size: 1024}
 	client := createTestClient(t, server.URL)
-	destPath := filepath.Join(t.TempDir(), "below.txt")
+	destPath := filepath.Join(t.TempDir(), "small.txt")
 
-	createPartialFile(t, destPath, content, int64(len(content)/2))
+	createPartialFile(t, destPath, content, 10)
 	downloadAndVerify(t, client, entry, destPath, content)
+	if rangeRequests != 1 {
+		t.Errorf("range requests = %d, want 1", rangeRequests)
+	}
 }
 
-func TestDownloadFile_AboveThreshold_ResumesFromPartial(t *testing.T) {
+func TestDownloadFile_LargePartial_ResumesFromPartial(t *testing.T) {
 	content := strings.Repeat("x", 110*1024)
 	server, entry := setupTestServer(t, content)
 	defer server.Close()
@@ -124,6 +136,21 @@ func TestDownloadFile_AboveThreshold_ResumesFromPartial(t *testing.T) {
 	downloadAndVerify(t, client, entry, destPath, content)
 }
 
+func TestDownloadFile_CompletePartial_Restart(t *testing.T) {
+	// A complete file left behind is no shorter than the remote file; the device answers
+	// the resume request with 416 and the file is downloaded again.
+	content := "complete but not renamed"
+	server, entry := setupTestServer(t, content)
+	defer server.Close()
+	entry.Size = 1024
+
+	client := createTestClient(t, server.URL)
+	destPath := filepath.Join(t.TempDir(), "complete.txt")
+
+	createPartialFile(t, destPath, content, int64(len(content)))
+	downloadAndVerify(t, client, entry, destPath, content)
+}
+
 func TestDownloadFile_LargeFile_FirstAttempt(t *testing.T) {
 	content := strings.Repeat("Large file content. ", 100000)
 	server, entry := setupTestServer(t, content)
@@ -301,3 +328,251 @@ func TestDownloadFile_NotFound(t *testing.T) {
 		t.Error("Expected error for 404, got nil")
 	}
 }
+
+func TestHead(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"/STR.edf": strings.Repeat("s", 21586),
+	})
+
+	entry, err := client.Stat(context.Background(), "/STR.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if entry.Size != 22*1024 {
+		t.Fatalf("expected KB-rounded size before Head, got %d", entry.Size)
+	}
+
+	if err := client.Head(context.Background(), entry); err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+	if entry.ExactSize != 21586 {
+		t.Errorf("ExactSize = %d, want 21586", entry.ExactSize)
+	}
+	if entry.ETag != `"5452"` {
+		t.Errorf("ETag = %q, want %q", entry.ETag, `"5452"`)
+	}
+	if entry.ContentType == "" {
+		t.Error("ContentType is empty")
+	}
+	if entry.ServerFilename != "str.edf" {
+		t.Errorf("ServerFilename = %q, want %q", entry.ServerFilename, "str.edf")
+	}
+}
+
+func TestHead_IgnoresRange(t *testing.T) {
+	content := "Range header ignored"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt"}
+	client := createTestClient(t, server.URL)
+	if err := client.Head(context.Background(), entry); err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+	if entry.ExactSize != int64(len(content)) {
+		t.Errorf("ExactSize = %d, want %d", entry.ExactSize, len(content))
+	}
+}
+
+func TestHead_NotFound(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	entry := &Entry{Name: "missing.edf", URL: "http://192.168.4.1/download?file=MISSING.EDF"}
+	if err := client.Head(context.Background(), entry); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Head error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParseContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"bytes 0-0/21586", 21586, false},
+		{"bytes */0", 0, false},
+		{"bytes 0-0/*", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseContentRangeTotal(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseContentRangeTotal(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseContentRangeTotal(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadFile_ResumeIgnoredRange(t *testing.T) {
+	content := strings.Repeat("Range ignored on resume. ", 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt", Size: int64(len(content))}
+	client := createTestClient(t, server.URL)
+	destPath := filepath.Join(t.TempDir(), "ignored.txt")
+
+	createPartialFile(t, destPath, content, int64(len(content)/2))
+	downloadAndVerify(t, client, entry, destPath, content)
+}
+
+// flakyServer serves content but drops the connection after dropAfter bytes of every
+// response, so that each request makes a little progress.
+func flakyServer(t *testing.T, content string, dropAfter int) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start := 0
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start); err != nil {
+				http.Error(w, "Invalid Range header", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)-start))
+		if start > 0 {
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.(http.Flusher).Flush()
+		end := min(start+dropAfter, len(content))
+		_, _ = w.Write([]byte(content[start:end]))
+		w.(http.Flusher).Flush()
+		if end < len(content) {
+			panic(http.ErrAbortHandler)
+		}
+	}))
+	return server, &requests
+}
+
+func TestDownloadFile_ResumesMidStream(t *testing.T) {
+	content := strings.Repeat("0123456789", 500)
+	server, requests := flakyServer(t, content, 1500)
+	defer server.Close()
+
+	entry := &Entry{Name: "flaky.txt", URL: server.URL + "/download?file=flaky.txt", Size: 5120}
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(3)))
+	destPath := filepath.Join(t.TempDir(), "flaky.txt")
+
+	downloadAndVerify(t, client, entry, destPath, content)
+	if *requests != 4 {
+		t.Errorf("requests = %d, want 4", *requests)
+	}
+}
+
+func TestGetFile_ResumesMidStream(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 100)
+	server, _ := flakyServer(t, content, 300)
+	defer server.Close()
+
+	entry := &Entry{Name: "flaky.txt", URL: server.URL + "/download?file=flaky.txt", Size: 1024}
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(3)))
+
+	reader, err := client.GetFile(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("Content mismatch: got length %d, want %d", len(got), len(content))
+	}
+}
+
+func TestGetFile_ResumeGivesUp(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 100)
+	server, _ := flakyServer(t, content, 0)
+	defer server.Close()
+
+	entry := &Entry{Name: "flaky.txt", URL: server.URL + "/download?file=flaky.txt", Size: 1024}
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(2)))
+
+	reader, err := client.GetFile(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Error("expected error when the connection never makes progress")
+	}
+}
+
+// shortReadBody returns its data together with io.ErrUnexpectedEOF in a single Read.
+type shortReadBody struct {
+	data string
+	done bool
+}
+
+func (b *shortReadBody) Read(p []byte) (int, error) {
+	if b.done {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b.done = true
+	return copy(p, b.data), io.ErrUnexpectedEOF
+}
+
+func (b *shortReadBody) Close() error { return nil }
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestGetFile_ResumesAfterDataWithError(t *testing.T) {
+	content := "0123456789abcdef"
+	var ranges []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		byteRange := req.Header.Get("Range")
+		if byteRange == "" {
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Request: req,
+				ContentLength: int64(len(content)), Body: &shortReadBody{data: content[:5]}}, nil
+		}
+		ranges = append(ranges, byteRange)
+		var start int
+		_, _ = fmt.Sscanf(byteRange, "bytes=%d-", &start)
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		return &http.Response{StatusCode: http.StatusPartialContent, Header: header, Request: req,
+			ContentLength: int64(len(content) - start), Body: io.NopCloser(strings.NewReader(content[start:]))}, nil
+	})
+
+	entry := &Entry{Name: "short.txt", URL: "http://card/download?file=short.txt", Size: 1024}
+	client := createTestClient(t, "http://card",
+		WithHTTPClient(&http.Client{Transport: transport}), WithRetryPolicy(fastRetryPolicy(3)))
+
+	reader, err := client.GetFile(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("expected %q, got %q", content, got)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=5-" {
+		t.Errorf("expected one resume at byte 5, got %v", ranges)
+	}
+}
@@ -0,0 +1,86 @@
+package ezshare
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// The device encodes file names and responses in GB2312. GBK is a superset of GB2312,
+// and is what the WHATWG encoding standard maps the "gb2312" label to.
+var deviceEncoding encoding.Encoding = simplifiedchinese.GBK
+
+// decodeBody returns a reader that converts an HTML body to UTF-8, according to the
+// charset declared in contentType or in the document's <meta> tag.
+func decodeBody(body io.Reader, contentType string) (io.Reader, error) {
+	r, err := charset.NewReader(body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return r, nil
+}
+
+// encodeDeviceString converts a UTF-8 string to the device encoding. Characters that
+// cannot be represented are replaced, so such names can only be reached via their 8.3 names.
+func encodeDeviceString(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	encoded, err := encoding.ReplaceUnsupported(deviceEncoding.NewEncoder()).String(s)
+	if err != nil {
+		return s
+	}
+	return encoded
+}
+
+// decodeDeviceString converts a string in the device encoding to UTF-8.
+func decodeDeviceString(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	decoded, err := deviceEncoding.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// escapeQueryValue percent-encodes a device path for use in a query string. Spaces are
+// encoded as %20 rather than "+", which the device does not decode.
+func escapeQueryValue(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(encodeDeviceString(s)), "+", "%20")
+}
+
+// encodeHref re-encodes the non-ASCII characters of a decoded listing href as
+// percent-escaped bytes in the device encoding, so that the URL addresses the same file
+// as the raw href did.
+func encodeHref(href string) string {
+	if isASCII(href) {
+		return href
+	}
+	var b strings.Builder
+	for _, r := range href {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+		for _, c := range []byte(encodeDeviceString(string(r))) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
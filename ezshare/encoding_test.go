@@ -0,0 +1,107 @@
+package ezshare
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildURL_EncodesDevicePath(t *testing.T) {
+	client := createTestClient(t, "http://192.168.4.1")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/DATALOG/STR.edf", "http://192.168.4.1/download?file=A%3A%5CDATALOG%5CSTR.edf"},
+		{"/a b&c#d+e.txt", "http://192.168.4.1/download?file=A%3A%5Ca%20b%26c%23d%2Be.txt"},
+		{"/照片/测试.jpg", "http://192.168.4.1/download?file=A%3A%5C%D5%D5%C6%AC%5C%B2%E2%CA%D4.jpg"},
+	}
+
+	for _, tt := range tests {
+		got := client.buildURL("/download", "file", convertUnixPathToAPI(tt.path))
+		if got != tt.expected {
+			t.Errorf("buildURL(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestParseDirectoryListing_GB2312(t *testing.T) {
+	listing := `<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=gb2312">
+</head>
+<body>
+<pre>
+   2026- 1- 4   10:55:58          64KB  <a href="http://192.168.4.1/download?file=` + "\xd5\xd5\xc6\xac~1.JPG" + `"> ` + "\xd5\xd5\xc6\xac\xb2\xe2\xca\xd4.jpg" + `</a>
+   2026- 1- 4   10:56:12         &lt;DIR&gt;   <a href="dir?dir=A:%5C%D5%D5%C6%AC"> ` + "\xd5\xd5\xc6\xac" + `</a>
+
+Total Entries: 2
+Total Size: 64KB
+</pre>
+</body>
+</html>`
+
+	body, err := decodeBody(bytes.NewReader([]byte(listing)), "text/html")
+	if err != nil {
+		t.Fatalf("decodeBody failed: %v", err)
+	}
+	parsed, err := parseDirectoryListing(body)
+	if err != nil {
+		t.Fatalf("parseDirectoryListing failed: %v", err)
+	}
+	entries := parsed.Entries
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Name != "照片测试.jpg" {
+		t.Errorf("expected name '照片测试.jpg', got %q", entries[0].Name)
+	}
+	if entries[0].URL != "http://192.168.4.1/download?file=%D5%D5%C6%AC~1.JPG" {
+		t.Errorf("expected raw GB2312 bytes to be percent-encoded, got %q", entries[0].URL)
+	}
+	if entries[0].ShortName != "照片~1.JPG" {
+		t.Errorf("expected short name '照片~1.JPG', got %q", entries[0].ShortName)
+	}
+	if entries[1].Name != "照片" {
+		t.Errorf("expected name '照片', got %q", entries[1].Name)
+	}
+}
+
+func TestNonASCIINames_RoundTrip(t *testing.T) {
+	name := "测试 & #1+.edf"
+	_, client := setupTestCard(t, map[string]string{
+		"/照片/" + name: "content",
+	})
+
+	entries, err := client.ListDirectory(context.Background(), "/照片")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != name {
+		t.Fatalf("expected single entry %q, got %+v", name, entries)
+	}
+
+	destPath := filepath.Join(t.TempDir(), name)
+	if err := client.DownloadFile(context.Background(), entries[0], destPath); err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+
+	byPathDest := filepath.Join(t.TempDir(), "by-path")
+	if err := client.DownloadFileByPath(context.Background(), "/照片/"+name, byPathDest); err != nil {
+		t.Fatalf("DownloadFileByPath failed: %v", err)
+	}
+
+	for _, p := range []string{destPath, byPathDest} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", p, err)
+		}
+		if string(data) != "content" {
+			t.Errorf("unexpected content %q in %s", data, p)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package ezshare
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 var (
 	// ErrNotFound is returned when a file or directory does not exist on the device.
@@ -9,4 +14,123 @@ var (
 	ErrInvalidResponse = errors.New("invalid response from device")
 	// ErrServerError is returned when the device returns a 5xx HTTP status code.
 	ErrServerError = errors.New("server error")
+	// ErrIncompleteListing is returned when a directory listing lacks its "Total Entries" /
+	// "Total Size" footer, typically because the response was truncated.
+	// It is retried by the default retry policy.
+	ErrIncompleteListing = errors.New("incomplete directory listing")
 )
+
+// StatusError is returned when the device answers a request with an unexpected HTTP status.
+// Errors with a 404 status match ErrNotFound, and errors with a 5xx status match ErrServerError.
+type StatusError struct {
+	// Op is the kind of request that failed, such as "list", "download", or "version".
+	Op   string
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code: %d", e.Op, e.URL, e.Code)
+}
+
+// Is reports whether the status code corresponds to target.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case ErrServerError:
+		return e.Code >= 500 && e.Code < 600
+	}
+	return false
+}
+
+// statusError creates a StatusError for the response to a request.
+func statusError(op string, resp *http.Response) *StatusError {
+	e := &StatusError{Op: op, Code: resp.StatusCode}
+	if resp.Request != nil {
+		e.URL = resp.Request.URL.String()
+	}
+	return e
+}
+
+// ParseError is returned when a response from the device cannot be parsed, such as a
+// directory listing line without a timestamp. It matches ErrInvalidResponse.
+type ParseError struct {
+	// Line is the offending part of the response, if there is one.
+	Line   string
+	Reason string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%v: %s", ErrInvalidResponse, e.Reason)
+	if e.Line != "" {
+		msg += fmt.Sprintf(" in %q", e.Line)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrInvalidResponse}
+	}
+	return []error{ErrInvalidResponse, e.Err}
+}
+
+// OpError records the operation and the remote path of a failed Client call. Errors
+// returned by the Client's methods are *OpError values wrapping the underlying cause.
+type OpError struct {
+	// Op is the failed operation, such as "list", "stat", "download", "head", or "read".
+	Op string
+	// Path is the Unix path on the device. It is empty for operations that don't concern
+	// a file, such as "version".
+	Path string
+	Err  error
+}
+
+func (e *OpError) Error() string {
+	if e.Path == "" {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// opError wraps err in an OpError, unless it is nil or already carries one.
+func opError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var opErr *OpError
+	if errors.As(err, &opErr) {
+		return err
+	}
+	return &OpError{Op: op, Path: path, Err: err}
+}
+
+// entryPath returns the Unix path of entry, or its name if the path is not known.
+func entryPath(entry *Entry) string {
+	if entry.Path != "" {
+		return entry.Path
+	}
+	return entry.Name
+}
+
+// UnsupportedFirmwareError is returned when there is no firmware driver for the chip
+// model reported by the device. Use WithFirmware to select a driver explicitly.
+type UnsupportedFirmwareError struct {
+	ChipModel string
+	// Version is the full version information of the device, if it was queried.
+	Version *Version
+}
+
+func (e *UnsupportedFirmwareError) Error() string {
+	return fmt.Sprintf("unsupported firmware %q (supported: %s)", e.ChipModel, strings.Join(Firmwares(), ", "))
+}
@@ -0,0 +1,129 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestStatusError_Is(t *testing.T) {
+	tests := []struct {
+		code        int
+		notFound    bool
+		serverError bool
+	}{
+		{404, true, false},
+		{500, false, true},
+		{503, false, true},
+		{403, false, false},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", &StatusError{Op: "list", URL: "http://card/dir", Code: tt.code})
+		if got := errors.Is(err, ErrNotFound); got != tt.notFound {
+			t.Errorf("HTTP %d: errors.Is(ErrNotFound) = %v", tt.code, got)
+		}
+		if got := errors.Is(err, ErrServerError); got != tt.serverError {
+			t.Errorf("HTTP %d: errors.Is(ErrServerError) = %v", tt.code, got)
+		}
+	}
+}
+
+func TestParseError_Unwrap(t *testing.T) {
+	_, cause := strconv.ParseInt("x", 10, 64)
+	err := error(&ParseError{Line: "   xKB", Reason: "invalid size", Err: cause})
+
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Error("ParseError should match ErrInvalidResponse")
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("ParseError should match its underlying error")
+	}
+	if IsRetryable(err) {
+		t.Error("parse errors should not be retried")
+	}
+}
+
+func TestOpError_ListNotFound(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	_, err := client.ListDirectory(context.Background(), "/MISSING")
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != "list" || opErr.Path != "/MISSING" {
+		t.Fatalf("expected list OpError for /MISSING, got %v", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound || statusErr.Op != "list" {
+		t.Errorf("expected 404 StatusError, got %v", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpError_StatNotFound(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	_, err := client.Stat(context.Background(), "/NOPE.edf")
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != "stat" || opErr.Path != "/NOPE.edf" {
+		t.Fatalf("expected stat OpError for /NOPE.edf, got %v", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpError_MalformedListing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><pre>   garbage   <a href="/download?file=X.EDF"> X.EDF</a>
+
+Total Entries: 1
+Total Size: 1KB
+</pre></body></html>`)
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL, WithFirmware(FirmwareLZ1801EDPG))
+	_, err := client.ListDirectory(context.Background(), "/DATALOG")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Reason != "timestamp not found" || parseErr.Line != "garbage" {
+		t.Fatalf("expected ParseError for the garbage line, got %v", err)
+	}
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Path != "/DATALOG" {
+		t.Errorf("expected OpError for /DATALOG, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidResponse) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected only ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestOpError_Unreachable(t *testing.T) {
+	card, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	entry, err := client.Stat(context.Background(), "/STR.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	card.Close()
+
+	client.retryPolicy = fastRetryPolicy(1)
+	err = client.Head(context.Background(), entry)
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != "head" || opErr.Path != "/STR.edf" {
+		t.Fatalf("expected head OpError for /STR.edf, got %v", err)
+	}
+	var netErr *net.OpError
+	if !errors.As(err, &netErr) {
+		t.Errorf("expected a network error, got %v", err)
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("unreachable card reported as a device error: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package ezsharetest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (h *Handler) serveDownload(w http.ResponseWriter, r *http.Request) {
+	n, err := h.resolve(decodeGBK(r.URL.Query().Get("file")))
+	if err != nil || n.info.IsDir() {
+		writeError(w, r, err)
+		return
+	}
+
+	f, err := os.Open(n.localPath)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	shortName := n.apiPath[strings.LastIndex(n.apiPath, "\\")+1:]
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(shortName)))
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ToLower(shortName)))
+	// The card derives its ETag from the file size in hex.
+	w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("%x", n.info.Size())))
+	http.ServeContent(w, r, "", n.info.ModTime(), f)
+}
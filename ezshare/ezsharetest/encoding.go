@@ -0,0 +1,35 @@
+package ezsharetest
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// The card encodes names and responses in GB2312, of which GBK is a superset.
+
+func encodeGBK(s string) string {
+	encoded, err := encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder()).String(s)
+	if err != nil {
+		return s
+	}
+	return encoded
+}
+
+func decodeGBK(s string) string {
+	decoded, err := simplifiedchinese.GBK.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// escapeQuery escapes a DOS path for a query string the way the card does: as GBK
+// bytes, leaving the drive colon as is.
+func escapeQuery(s string) string {
+	escaped := url.QueryEscape(encodeGBK(s))
+	escaped = strings.ReplaceAll(escaped, "+", "%20")
+	return strings.ReplaceAll(escaped, "%3A", ":")
+}
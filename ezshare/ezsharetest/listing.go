@@ -0,0 +1,96 @@
+package ezsharetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"time"
+)
+
+func (h *Handler) serveDir(w http.ResponseWriter, r *http.Request) {
+	n, err := h.resolve(decodeGBK(r.URL.Query().Get("dir")))
+	if err != nil || !n.info.IsDir() {
+		writeError(w, r, err)
+		return
+	}
+
+	children, err := readChildren(n.localPath)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	title := "A:"
+	if n.apiPath != "" {
+		title = n.info.Name()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=gb2312">
+<title>Index of %s</title>
+</head>
+<body>
+<h1><a href="photo">back to photo</a></h1>
+<h1>Directory Index of %s</h1>
+<pre>
+`, html.EscapeString(title), html.EscapeString(title))
+
+	count := 0
+	var totalKB int64
+	if n.apiPath != "" {
+		h.writeDirLine(&buf, n.info, dirHref(n.apiPath), ".")
+		h.writeDirLine(&buf, n.info, dirHref(parentAPIPath(n.apiPath)), "..")
+		count += 2
+	}
+	for _, c := range children {
+		apiPath := joinAPIPath(n.apiPath, c.shortName)
+		if c.info.IsDir() {
+			h.writeDirLine(&buf, c.info, dirHref(apiPath), c.info.Name())
+		} else {
+			sizeKB := (c.info.Size() + 1023) / 1024
+			totalKB += sizeKB
+			href := "http://" + h.hrefHost(r) + "/download?file=" + escapeQuery(apiPath)
+			fmt.Fprintf(&buf, "   %s %14s  <a href=\"%s\"> %s</a>\n",
+				h.formatTimestamp(c.info.ModTime()), fmt.Sprintf("%dKB", sizeKB), href, html.EscapeString(c.info.Name()))
+		}
+		count++
+	}
+
+	fmt.Fprintf(&buf, "\nTotal Entries: %d\nTotal Size: %dKB\n</pre>\n</body>\n</html>", count, totalKB)
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write([]byte(encodeGBK(buf.String())))
+}
+
+func (h *Handler) writeDirLine(buf *bytes.Buffer, info fs.FileInfo, href, name string) {
+	fmt.Fprintf(buf, "   %s         &lt;DIR&gt;   <a href=\"%s\"> %s</a>\n",
+		h.formatTimestamp(info.ModTime()), href, html.EscapeString(name))
+}
+
+// formatTimestamp renders t the way the card does: space-padded instead of zero-padded,
+// e.g. "2026- 1- 5    5: 8:56".
+func (h *Handler) formatTimestamp(t time.Time) string {
+	t = t.In(h.location())
+	return fmt.Sprintf("%4d-%2d-%2d   %2d:%2d:%2d", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+func dirHref(apiPath string) string {
+	if apiPath == "" {
+		return "dir?dir=A:"
+	}
+	return "dir?dir=" + escapeQuery("A:\\"+apiPath)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil || errors.Is(err, errNotFound) || errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
@@ -0,0 +1,110 @@
+package ezsharetest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errNotFound = errors.New("not found")
+
+// node is a file or directory on the fake card.
+type node struct {
+	localPath string
+	// apiPath is the DOS path of the node built from 8.3 names, without the drive
+	// letter (e.g. "DATALOG\20260104"). It is empty for the root directory.
+	apiPath string
+	info    fs.FileInfo
+}
+
+// child is a directory entry together with its 8.3 name.
+type child struct {
+	info      fs.FileInfo
+	shortName string
+}
+
+// resolve maps a DOS path such as "A:\DATALOG\20260104" or "DATALOG\20FL2G~1.EDF" to a
+// local file. Like the real card, only 8.3 names are understood, and matching is
+// case-insensitive as on FAT.
+func (h *Handler) resolve(apiPath string) (*node, error) {
+	if len(apiPath) >= 2 && strings.EqualFold(apiPath[:2], "A:") {
+		apiPath = apiPath[2:]
+	}
+
+	info, err := os.Stat(h.Root)
+	if err != nil {
+		return nil, err
+	}
+	current := &node{localPath: h.Root, info: info}
+
+	for _, part := range strings.Split(apiPath, "\\") {
+		if part == "" {
+			continue
+		}
+		if !current.info.IsDir() {
+			return nil, errNotFound
+		}
+		children, err := readChildren(current.localPath)
+		if err != nil {
+			return nil, err
+		}
+		var found *child
+		for i := range children {
+			if strings.EqualFold(children[i].shortName, part) {
+				found = &children[i]
+				break
+			}
+		}
+		if found == nil {
+			return nil, errNotFound
+		}
+		current = &node{
+			localPath: filepath.Join(current.localPath, found.info.Name()),
+			apiPath:   joinAPIPath(current.apiPath, found.shortName),
+			info:      found.info,
+		}
+	}
+	return current, nil
+}
+
+func readChildren(dir string) ([]child, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(dirEntries))
+	infos := make([]fs.FileInfo, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		info, err := de.Info()
+		if err != nil {
+			// The file disappeared between ReadDir and Info; the card would not list it either.
+			continue
+		}
+		names = append(names, de.Name())
+		infos = append(infos, info)
+	}
+
+	short := shortNames(names)
+	children := make([]child, len(infos))
+	for i, info := range infos {
+		children[i] = child{info: info, shortName: short[info.Name()]}
+	}
+	return children, nil
+}
+
+func joinAPIPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "\\" + name
+}
+
+func parentAPIPath(apiPath string) string {
+	if i := strings.LastIndex(apiPath, "\\"); i >= 0 {
+		return apiPath[:i]
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+// Package ezsharetest provides a fake EZ-Share WiFi SD card for tests and demos.
+//
+// The fake card serves a local directory tree over HTTP and reproduces the quirks of
+// the real hardware described in API.md: HTML <pre> directory listings with unpadded
+// timestamps and KB-rounded sizes, DOS 8.3 names in links, absolute download URLs
+// pointing at 192.168.4.1, HTTP range requests, and the XML version endpoint.
+package ezsharetest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+const (
+	// DefaultVersion is the version string reported by the LZ1801EDPG firmware.
+	DefaultVersion = "LZ1801EDPG:1.0.0:2016-03-19:72 LZ1801EDRS:1.0.0:2016-03-19:72 SPEED:-H:SPEED"
+	// DefaultHrefHost is the host the real card embeds in absolute download links.
+	DefaultHrefHost = "192.168.4.1"
+)
+
+// Handler serves a local directory tree using the EZ-Share HTTP API.
+type Handler struct {
+	// Root is the local directory exposed as the card's A: drive.
+	Root string
+	// Version is returned by /client?command=version.
+	Version string
+	// HrefHost is the host embedded in absolute download links. If empty, the Host
+	// header of the incoming request is used.
+	HrefHost string
+	// Location is the time zone used to render listing timestamps. Defaults to time.Local.
+	Location *time.Location
+}
+
+// NewHandler creates a Handler for the given root directory that behaves like an
+// LZ1801EDPG card.
+func NewHandler(root string) *Handler {
+	return &Handler{
+		Root:     root,
+		Version:  DefaultVersion,
+		HrefHost: DefaultHrefHost,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/dir":
+		h.serveDir(w, r)
+	case "/download":
+		h.serveDownload(w, r)
+	case "/client":
+		h.serveClient(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) location() *time.Location {
+	if h.Location != nil {
+		return h.Location
+	}
+	return time.Local
+}
+
+func (h *Handler) hrefHost(r *http.Request) string {
+	if h.HrefHost != "" {
+		return h.HrefHost
+	}
+	return r.Host
+}
+
+// Server is a fake EZ-Share card listening on a local loopback address.
+type Server struct {
+	*httptest.Server
+	// Handler is the handler serving the card's contents. Its fields may be changed
+	// while the server is running.
+	Handler *Handler
+}
+
+// NewServer starts a fake card serving the given root directory.
+// The caller should call Close when finished, to shut it down.
+func NewServer(root string) *Server {
+	h := NewHandler(root)
+	return &Server{
+		Server:  httptest.NewServer(h),
+		Handler: h,
+	}
+}
+
+// Client returns an HTTP client that sends every request to the fake card, including
+// requests for the absolute 192.168.4.1 download links found in listings.
+func (s *Server) Client() *http.Client {
+	addr := s.Listener.Addr().String()
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
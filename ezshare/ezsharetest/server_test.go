@@ -0,0 +1,182 @@
+package ezsharetest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haimgel/ezshare-sync/ezshare"
+	"github.com/haimgel/ezshare-sync/ezshare/ezsharetest"
+)
+
+func writeCardFile(t *testing.T, root, path, content string, modTime time.Time) {
+	t.Helper()
+	fullPath := filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Chtimes(fullPath, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set timestamp: %v", err)
+	}
+}
+
+func setupCard(t *testing.T) (*ezsharetest.Server, *ezshare.Client) {
+	t.Helper()
+	root := t.TempDir()
+	modTime := time.Date(2026, 1, 5, 5, 8, 56, 0, time.UTC)
+	writeCardFile(t, root, "STR.edf", strings.Repeat("s", 21586), modTime)
+	writeCardFile(t, root, "Identification.tgt", "id", modTime)
+	writeCardFile(t, root, "DATALOG/20260104/20260104_234156_BRP.edf", strings.Repeat("b", 5000), modTime)
+
+	server := ezsharetest.NewServer(root)
+	server.Handler.Location = time.UTC
+	t.Cleanup(server.Close)
+
+	client, err := ezshare.NewClient(server.URL, ezshare.WithHTTPClient(server.Client()),
+		ezshare.WithDeviceLocation(time.UTC), ezshare.WithHrefRewriting(false))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return server, client
+}
+
+func TestServer_ListDirectory(t *testing.T) {
+	_, client := setupCard(t)
+
+	entries, err := client.ListDirectory(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	byName := make(map[string]*ezshare.Entry)
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+
+	str := byName["STR.edf"]
+	if str == nil {
+		t.Fatal("STR.edf not listed")
+	}
+	if str.Size != 22*1024 {
+		t.Errorf("expected KB-rounded size %d, got %d", 22*1024, str.Size)
+	}
+	if !str.Timestamp.Equal(time.Date(2026, 1, 5, 5, 8, 56, 0, time.UTC)) {
+		t.Errorf("unexpected timestamp %v", str.Timestamp)
+	}
+	if str.URL != "http://192.168.4.1/download?file=STR.EDF" {
+		t.Errorf("unexpected URL %q", str.URL)
+	}
+
+	id := byName["Identification.tgt"]
+	if id == nil || !strings.Contains(id.URL, "IDENTI~1.TGT") {
+		t.Errorf("expected 8.3 name in URL, got %+v", id)
+	}
+
+	if dir := byName["DATALOG"]; dir == nil || !dir.IsDir {
+		t.Errorf("expected DATALOG directory, got %+v", dir)
+	}
+}
+
+func TestServer_ListSubdirectory(t *testing.T) {
+	_, client := setupCard(t)
+
+	entries, err := client.ListDirectory(context.Background(), "/DATALOG/20260104")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Name != "20260104_234156_BRP.edf" {
+		t.Errorf("unexpected name %q", entries[0].Name)
+	}
+	if entries[0].URL != "http://192.168.4.1/download?file=DATALOG%5C20260104%5C202601~1.EDF" {
+		t.Errorf("unexpected URL %q", entries[0].URL)
+	}
+}
+
+func TestServer_ListDirectory_NotFound(t *testing.T) {
+	_, client := setupCard(t)
+
+	_, err := client.ListDirectory(context.Background(), "/MISSING")
+	if err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestServer_DownloadFile(t *testing.T) {
+	_, client := setupCard(t)
+
+	entries, err := client.ListDirectory(context.Background(), "/DATALOG/20260104")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	if err := client.DownloadFile(context.Background(), entries[0], destPath); err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+	data, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded file: %v", err)
+	}
+	if string(data) != strings.Repeat("b", 5000) {
+		t.Errorf("content mismatch: got %d bytes", len(data))
+	}
+}
+
+func TestServer_DownloadHeadersAndRange(t *testing.T) {
+	server, _ := setupCard(t)
+
+	req, err := http.NewRequest("GET", "http://192.168.4.1/download?file=STR.EDF", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Range", "bytes=21580-")
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Range"); got != "bytes 21580-21585/21586" {
+		t.Errorf("unexpected Content-Range %q", got)
+	}
+	if got := resp.Header.Get("ETag"); got != `"5452"` {
+		t.Errorf("unexpected ETag %q", got)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="str.edf"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ssssss" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestServer_GetVersion(t *testing.T) {
+	_, client := setupCard(t)
+
+	version, err := client.GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if version.ChipModel != "LZ1801EDPG" || version.BuildNumber != "72" {
+		t.Errorf("unexpected version %+v", version)
+	}
+}
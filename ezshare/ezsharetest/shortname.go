@@ -0,0 +1,82 @@
+package ezsharetest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shortNames assigns DOS 8.3 names to the given long names the way a FAT driver would,
+// returning a map from long name to short name. Names are processed in order, so the
+// result is stable for a sorted directory listing.
+func shortNames(names []string) map[string]string {
+	result := make(map[string]string, len(names))
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		short := shortName(name, taken)
+		taken[short] = true
+		result[name] = short
+	}
+	return result
+}
+
+func shortName(long string, taken map[string]bool) string {
+	if upper := strings.ToUpper(long); isValid83(upper) && !taken[upper] {
+		return upper
+	}
+
+	base, ext := long, ""
+	if i := strings.LastIndex(long, "."); i > 0 {
+		base, ext = long[:i], long[i+1:]
+	}
+	base = sanitize83(base)
+	ext = sanitize83(ext)
+	if len(ext) > 3 {
+		ext = ext[:3]
+	}
+	if base == "" {
+		base = "_"
+	}
+
+	for n := 1; ; n++ {
+		tail := fmt.Sprintf("~%d", n)
+		prefix := base
+		if len(prefix) > 8-len(tail) {
+			prefix = prefix[:8-len(tail)]
+		}
+		candidate := prefix + tail
+		if ext != "" {
+			candidate += "." + ext
+		}
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+func isValid83(name string) bool {
+	base, ext := name, ""
+	if i := strings.Index(name, "."); i >= 0 {
+		base, ext = name[:i], name[i+1:]
+	}
+	if base == "" || len(base) > 8 || len(ext) > 3 || strings.Contains(ext, ".") {
+		return false
+	}
+	return sanitize83(base) == base && sanitize83(ext) == ext
+}
+
+// sanitize83 upper-cases s, drops spaces and dots, and replaces characters that are
+// not allowed in 8.3 names with underscores.
+func sanitize83(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		switch {
+		case r == ' ' || r == '.':
+			continue
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("!#$%&'()-@^_`{}~", r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
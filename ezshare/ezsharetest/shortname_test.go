@@ -0,0 +1,33 @@
+package ezsharetest
+
+import "testing"
+
+func TestShortNames(t *testing.T) {
+	names := []string{
+		"STR.edf",
+		"Journal.dat",
+		"DATALOG",
+		"Identification.tgt",
+		"20260104_234139_CSL.edf",
+		"20260104_234156_BRP.edf",
+		"System Volume Information",
+		"archive.tar.gz",
+	}
+	want := map[string]string{
+		"STR.edf":                   "STR.EDF",
+		"Journal.dat":               "JOURNAL.DAT",
+		"DATALOG":                   "DATALOG",
+		"Identification.tgt":        "IDENTI~1.TGT",
+		"20260104_234139_CSL.edf":   "202601~1.EDF",
+		"20260104_234156_BRP.edf":   "202601~2.EDF",
+		"System Volume Information": "SYSTEM~1",
+		"archive.tar.gz":            "ARCHIV~1.GZ",
+	}
+
+	got := shortNames(names)
+	for long, short := range want {
+		if got[long] != short {
+			t.Errorf("shortNames()[%q] = %q, want %q", long, got[long], short)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package ezsharetest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const versionTemplate = `<?xml version="1.0" encoding="gb2312"?>
+<response>
+<device>
+<version>%s</version>
+</device>
+</response>
+`
+
+const defaultClientResponse = `<?xml version="1.0" encoding="gb2312"?>
+<response>
+this is ezshare!</response>
+`
+
+func (h *Handler) serveClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/xml")
+	if r.URL.Query().Get("command") == "version" {
+		_, _ = fmt.Fprintf(w, versionTemplate, h.Version)
+		return
+	}
+	_, _ = w.Write([]byte(defaultClientResponse))
+}
@@ -0,0 +1,151 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Firmware is a driver for the HTTP API of a particular card firmware. Different
+// firmwares use different listing formats and URL schemes; a driver converts paths,
+// builds request URLs, and parses directory listings for one of them.
+type Firmware interface {
+	// ChipModel returns the chip model handled by this driver, as reported by
+	// GetVersion (e.g. "LZ1801EDPG").
+	ChipModel() string
+	// APIPath converts a clean, absolute Unix-style path to the device path notation.
+	APIPath(unixPath string) string
+	// ListURL returns the URL that lists the directory at apiPath.
+	ListURL(baseURL *url.URL, apiPath string) string
+	// DownloadURL returns the URL that downloads the file at apiPath.
+	DownloadURL(baseURL *url.URL, apiPath string) string
+	// ParseListing parses a directory listing response, already decoded to UTF-8.
+	// The "." and ".." entries must be omitted. Timestamps are the device's wall-clock
+	// times expressed in UTC; the client converts them to the device location. Lines
+	// that cannot be parsed are reported as Listing.Warnings rather than as an error.
+	// A listing that appears truncated must fail with an error wrapping
+	// ErrIncompleteListing, so that it is retried.
+	ParseListing(r io.Reader) (*Listing, error)
+}
+
+// FirmwareLZ1801EDPG is the driver for the LZ1801EDPG firmware documented in API.md.
+var FirmwareLZ1801EDPG Firmware = lz1801Firmware{}
+
+// nolint: gochecknoglobals
+var (
+	firmwaresMu sync.RWMutex
+	firmwares   = map[string]Firmware{
+		"LZ1801EDPG": FirmwareLZ1801EDPG,
+	}
+)
+
+// RegisterFirmware makes a firmware driver available for automatic selection by chip
+// model. If RegisterFirmware is called twice for the same chip model, it panics.
+func RegisterFirmware(fw Firmware) {
+	firmwaresMu.Lock()
+	defer firmwaresMu.Unlock()
+
+	if fw == nil {
+		panic("ezshare: RegisterFirmware driver is nil")
+	}
+	key := strings.ToUpper(fw.ChipModel())
+	if _, dup := firmwares[key]; dup {
+		panic("ezshare: RegisterFirmware called twice for " + key)
+	}
+	firmwares[key] = fw
+}
+
+// LookupFirmware returns the registered driver for a chip model. If there is none,
+// the error is an *UnsupportedFirmwareError.
+func LookupFirmware(chipModel string) (Firmware, error) {
+	firmwaresMu.RLock()
+	defer firmwaresMu.RUnlock()
+
+	if fw, ok := firmwares[strings.ToUpper(chipModel)]; ok {
+		return fw, nil
+	}
+	return nil, &UnsupportedFirmwareError{ChipModel: chipModel}
+}
+
+// Firmwares returns the chip models of all registered firmware drivers, sorted.
+func Firmwares() []string {
+	firmwaresMu.RLock()
+	defer firmwaresMu.RUnlock()
+
+	names := make([]string, 0, len(firmwares))
+	for name := range firmwares {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectFirmware returns the firmware driver used by the client. Unless one was set with
+// WithFirmware, it is selected from the chip model reported by GetVersion the first time
+// it is needed, and cached. Unknown chip models fail with an *UnsupportedFirmwareError.
+func (c *Client) DetectFirmware(ctx context.Context) (Firmware, error) {
+	c.firmwareMu.Lock()
+	fw := c.firmware
+	c.firmwareMu.Unlock()
+	if fw != nil {
+		return fw, nil
+	}
+
+	// The request is made without the lock, so that a slow or retried one doesn't hold up
+	// other callers; they make their own
+	version, err := c.GetVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect firmware: %w", err)
+	}
+	return c.firmwareForVersion(version)
+}
+
+// firmwareForVersion returns the client's firmware if it is already known, or else looks
+// it up from a version the caller already fetched and caches it.
+func (c *Client) firmwareForVersion(version *Version) (Firmware, error) {
+	c.firmwareMu.Lock()
+	defer c.firmwareMu.Unlock()
+
+	if c.firmware != nil {
+		return c.firmware, nil
+	}
+	fw, err := LookupFirmware(version.ChipModel)
+	if err != nil {
+		var unsupported *UnsupportedFirmwareError
+		if errors.As(err, &unsupported) {
+			unsupported.Version = version
+		}
+		return nil, err
+	}
+	c.firmware = fw
+	return fw, nil
+}
+
+// lz1801Firmware implements the LZ1801EDPG API: DOS paths with an "A:" drive letter,
+// and HTML <pre> listings parsed by parseDirectoryListing.
+type lz1801Firmware struct{}
+
+func (lz1801Firmware) ChipModel() string {
+	return "LZ1801EDPG"
+}
+
+func (lz1801Firmware) APIPath(unixPath string) string {
+	return convertUnixPathToAPI(unixPath)
+}
+
+func (lz1801Firmware) ListURL(baseURL *url.URL, apiPath string) string {
+	return buildDeviceURL(baseURL, "/dir", "dir", apiPath)
+}
+
+func (lz1801Firmware) DownloadURL(baseURL *url.URL, apiPath string) string {
+	return buildDeviceURL(baseURL, "/download", "file", apiPath)
+}
+
+func (lz1801Firmware) ParseListing(r io.Reader) (*Listing, error) {
+	return parseDirectoryListing(r)
+}
@@ -0,0 +1,150 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDetectFirmware(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	var versionRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/client" {
+			versionRequests.Add(1)
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := createTestClient(t, server.URL)
+
+	fw, err := client.DetectFirmware(context.Background())
+	if err != nil {
+		t.Fatalf("DetectFirmware failed: %v", err)
+	}
+	if fw != FirmwareLZ1801EDPG {
+		t.Errorf("expected LZ1801EDPG driver, got %v", fw.ChipModel())
+	}
+
+	for range 3 {
+		if _, err := client.ListDirectory(context.Background(), "/"); err != nil {
+			t.Fatalf("ListDirectory failed: %v", err)
+		}
+	}
+	if got := versionRequests.Load(); got != 1 {
+		t.Errorf("expected firmware to be detected once, got %d version requests", got)
+	}
+}
+
+func TestDetectFirmware_SlowRequestDoesNotBlock(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	// The first version request hangs until released; later ones are answered
+	var versionRequests atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/client" && versionRequests.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	defer close(release)
+	client := createTestClient(t, server.URL)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.DetectFirmware(context.Background())
+		slow <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.DetectFirmware(ctx); err != nil {
+		t.Fatalf("DetectFirmware blocked behind the slow request: %v", err)
+	}
+	release <- struct{}{}
+	if err := <-slow; err != nil {
+		t.Errorf("slow DetectFirmware failed: %v", err)
+	}
+}
+
+func TestDetectFirmware_Unsupported(t *testing.T) {
+	card, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	card.Handler.Version = "LZ1001EDPG:2.0.1:2020-01-15:100"
+
+	_, err := client.ListDirectory(context.Background(), "/")
+	var unsupported *UnsupportedFirmwareError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedFirmwareError, got %v", err)
+	}
+	if unsupported.ChipModel != "LZ1001EDPG" {
+		t.Errorf("ChipModel = %q, want LZ1001EDPG", unsupported.ChipModel)
+	}
+	if unsupported.Version == nil || unsupported.Version.BuildNumber != "100" {
+		t.Errorf("expected version details, got %+v", unsupported.Version)
+	}
+}
+
+func TestWithFirmware(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	card.Handler.Version = "LZ1001EDPG:2.0.1:2020-01-15:100"
+
+	client := createTestClient(t, card.URL, WithHTTPClient(card.Client()), WithFirmware(FirmwareLZ1801EDPG))
+	entries, err := client.ListDirectory(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry, got %d", len(entries))
+	}
+}
+
+// testFirmware wraps the LZ1801EDPG driver under a different chip model.
+type testFirmware struct {
+	Firmware
+	parsed atomic.Int32
+}
+
+func (f *testFirmware) ChipModel() string { return "TESTCHIP" }
+
+func (f *testFirmware) ParseListing(r io.Reader) (*Listing, error) {
+	f.parsed.Add(1)
+	return f.Firmware.ParseListing(r)
+}
+
+func TestRegisterFirmware(t *testing.T) {
+	fw := &testFirmware{Firmware: FirmwareLZ1801EDPG}
+	RegisterFirmware(fw)
+	t.Cleanup(func() {
+		firmwaresMu.Lock()
+		defer firmwaresMu.Unlock()
+		delete(firmwares, "TESTCHIP")
+	})
+
+	card, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	card.Handler.Version = "TESTCHIP:1.0.0:2026-01-01:1"
+
+	if _, err := client.ListDirectory(context.Background(), "/"); err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if fw.parsed.Load() != 1 {
+		t.Errorf("expected registered driver to parse the listing")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	RegisterFirmware(fw)
+}
@@ -0,0 +1,269 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FS is a read-only view of the device's file system. It implements fs.FS,
+// fs.ReadDirFS, and fs.StatFS, so it can be used with fs.WalkDir, fs.Glob,
+// http.FS, and other standard library helpers.
+type FS struct {
+	ctx    context.Context
+	client *Client
+}
+
+var (
+	_ fs.FS        = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+// FS returns a file system view of the device. All operations on the returned FS
+// use ctx for their requests.
+func (c *Client) FS(ctx context.Context) *FS {
+	return &FS{ctx: ctx, client: c}
+}
+
+// Open opens the named file or directory. Directory listings are fetched on the first
+// ReadDir call. Files implement io.ReadSeeker and io.ReaderAt, and are read with range
+// requests, so http.FileServer can serve them.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	entry, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir {
+		return &dirFile{fsys: fsys, name: name, entry: entry}, nil
+	}
+	reader, err := fsys.client.OpenReaderAt(fsys.ctx, entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fsError(err)}
+	}
+	return &remoteFile{name: name, entry: entry, reader: reader}, nil
+}
+
+// ReadDir reads the named directory and returns its entries sorted by filename. The
+// exact size of a file is fetched with Head when its entry's Info method is called.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, err := fsys.client.ListDirectory(fsys.ctx, toDevicePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fsError(err)}
+	}
+	return fsys.toDirEntries(entries), nil
+}
+
+// Stat returns a fs.FileInfo describing the named file or directory. The exact size of
+// a file is fetched with Head.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	entry, err := fsys.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{entry}, nil
+}
+
+func (fsys *FS) stat(op, name string) (*Entry, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &Entry{Name: ".", Path: "/", IsDir: true}, nil
+	}
+
+	entry, err := fsys.client.Stat(fsys.ctx, toDevicePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+	}
+	if !entry.IsDir {
+		if err := fsys.client.Head(fsys.ctx, entry); err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+		}
+	}
+	return entry, nil
+}
+
+// validPath reports whether name is a valid fs.FS path. Backslashes are rejected as
+// well, since the device uses them as path separators.
+func validPath(name string) bool {
+	return fs.ValidPath(name) && !strings.Contains(name, "\\")
+}
+
+// toDevicePath converts an unrooted fs.FS path to a Unix-style device path.
+func toDevicePath(name string) string {
+	if name == "." {
+		return "/"
+	}
+	return "/" + name
+}
+
+// fsError maps client errors to the sentinel errors used by io/fs.
+func fsError(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return fs.ErrNotExist
+	}
+	return err
+}
+
+func (fsys *FS) toDirEntries(entries []*Entry) []fs.DirEntry {
+	result := make([]fs.DirEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = &dirEntry{fsys: fsys, entry: entry}
+	}
+	slices.SortFunc(result, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return result
+}
+
+// dirEntry adapts an Entry from a listing to fs.DirEntry.
+type dirEntry struct {
+	fsys  *FS
+	entry *Entry
+}
+
+func (d *dirEntry) Name() string      { return d.entry.Name }
+func (d *dirEntry) IsDir() bool       { return d.entry.IsDir }
+func (d *dirEntry) Type() fs.FileMode { return fileInfo{d.entry}.Mode().Type() }
+func (d *dirEntry) String() string    { return fs.FormatDirEntry(d) }
+
+// Info returns the entry's fs.FileInfo, fetching the exact size of a file with Head
+// the first time it is called, since listings round sizes up to a whole KB.
+func (d *dirEntry) Info() (fs.FileInfo, error) {
+	if !d.entry.IsDir && d.entry.ExactSize == 0 && d.entry.Size > 0 {
+		if err := d.fsys.client.Head(d.fsys.ctx, d.entry); err != nil {
+			return nil, &fs.PathError{Op: "stat", Path: d.entry.Name, Err: fsError(err)}
+		}
+	}
+	return fileInfo{d.entry}, nil
+}
+
+// fileInfo adapts an Entry to fs.FileInfo.
+type fileInfo struct {
+	entry *Entry
+}
+
+func (fi fileInfo) Name() string { return fi.entry.Name }
+
+// Size returns the exact size of the file if it was fetched with Head, and the size
+// from the listing otherwise.
+func (fi fileInfo) Size() int64 {
+	if fi.entry.ExactSize > 0 {
+		return fi.entry.ExactSize
+	}
+	return fi.entry.Size
+}
+
+func (fi fileInfo) ModTime() time.Time { return fi.entry.Timestamp }
+func (fi fileInfo) IsDir() bool        { return fi.entry.IsDir }
+
+// Sys returns the underlying *Entry.
+func (fi fileInfo) Sys() any { return fi.entry }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.entry.IsDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// remoteFile is a file opened through FS, read through a FileReader.
+type remoteFile struct {
+	name   string
+	entry  *Entry
+	reader *FileReader
+	closed bool
+}
+
+var (
+	_ io.ReadSeeker = (*remoteFile)(nil)
+	_ io.ReaderAt   = (*remoteFile)(nil)
+)
+
+func (f *remoteFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{f.entry}, nil
+}
+
+func (f *remoteFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *remoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.ReadAt(p, off)
+}
+
+func (f *remoteFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *remoteFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return f.reader.Close()
+}
+
+// dirFile is a directory opened through FS. It implements fs.ReadDirFile.
+type dirFile struct {
+	fsys    *FS
+	name    string
+	entry   *Entry
+	entries []fs.DirEntry
+	loaded  bool
+	offset  int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{d.entry}, nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.loaded = true
+	}
+
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,125 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS_TestFS(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	err := fstest.TestFS(client.FS(context.Background()),
+		"STR.edf",
+		"DATALOG/20260104/20260104_234139_CSL.edf",
+		"DATALOG/20260105/20260105_230000_EVE.edf",
+		"SETTINGS/CurrentSettings.json",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFS_ReadFile(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	data, err := fs.ReadFile(client.FS(context.Background()), "DATALOG/20260104/20260104_234156_BRP.edf")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != "brp" {
+		t.Errorf("ReadFile = %q, want %q", data, "brp")
+	}
+}
+
+func TestFS_Glob(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	matches, err := fs.Glob(client.FS(context.Background()), "DATALOG/*/*_BRP.edf")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	want := []string{"DATALOG/20260104/20260104_234156_BRP.edf"}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob = %v, want %v", matches, want)
+	}
+}
+
+func TestFS_NotExist(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+	fsys := client.FS(context.Background())
+
+	if _, err := fsys.Stat("DATALOG/missing.edf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := fsys.Open("MISSING/file.edf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := fsys.Open("/STR.edf"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Open error = %v, want fs.ErrInvalid", err)
+	}
+}
+
+func TestFS_ExactSizes(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"DATALOG/STR.edf": strings.Repeat("s", 1500)})
+	fsys := client.FS(context.Background())
+
+	info, err := fsys.Stat("DATALOG/STR.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() != 1500 {
+		t.Errorf("Stat size = %d, want 1500", info.Size())
+	}
+
+	entries, err := fsys.ReadDir("DATALOG")
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	info, err = entries[0].Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Size() != 1500 {
+		t.Errorf("entry size = %d, want 1500", info.Size())
+	}
+}
+
+func TestFS_FileServer(t *testing.T) {
+	content := edfContent(100 * 1024)
+	_, client := setupTestCard(t, map[string]string{"DATALOG/BRP.edf": content})
+	server := httptest.NewServer(http.FileServer(http.FS(client.FS(context.Background()))))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/DATALOG/BRP.edf")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body %q", resp.StatusCode, body)
+	}
+	if resp.ContentLength != int64(len(content)) || string(body) != content {
+		t.Errorf("got %d bytes (Content-Length %d), want %d", len(body), resp.ContentLength, len(content))
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/DATALOG/BRP.edf", nil)
+	req.Header.Set("Range", "bytes=50000-50009")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("range GET failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != content[50000:50010] {
+		t.Errorf("range response %d %q, want 206 %q", resp.StatusCode, body, content[50000:50010])
+	}
+}
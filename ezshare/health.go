@@ -0,0 +1,122 @@
+package ezshare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// probeReadSize is the number of bytes Probe reads to measure throughput.
+	probeReadSize = 64 * 1024
+	// probeMinFileSize is the smallest listed size of a file Probe reads. Smaller reads
+	// take about as long as a request with no data, so their throughput means little.
+	probeMinFileSize = 16 * 1024
+)
+
+// PingResult is the result of Ping.
+type PingResult struct {
+	// Latency is the round-trip time of the version request.
+	Latency time.Duration
+	Version *Version
+}
+
+// ProbeResult is the result of Probe.
+type ProbeResult struct {
+	PingResult
+	// ListingLatency is the time taken to list the root directory.
+	ListingLatency time.Duration
+	// File is the path of the file read to measure throughput. It is empty, and the
+	// throughput is not measured, if the root directory holds no file of at least 16 KiB.
+	File string
+	// BytesRead is the number of bytes read from File, at most 64 KiB.
+	BytesRead int64
+	// ReadDuration is the time taken by the read, from sending the request to the last byte.
+	ReadDuration   time.Duration
+	BytesPerSecond float64
+}
+
+// Ping requests the firmware version from the device, and reports how long it took.
+// Unlike other methods, Ping is not retried: a card that is asleep or out of range
+// fails right away, typically with a timeout or a *net.OpError.
+func (c *Client) Ping(ctx context.Context) (*PingResult, error) {
+	start := time.Now()
+	version, err := c.getVersionAttempt(ctx)
+	if err != nil {
+		return nil, opError("ping", "", err)
+	}
+	return &PingResult{Latency: time.Since(start), Version: version}, nil
+}
+
+// Probe measures the quality of the link to the device: it pings it, lists the root
+// directory, and reads the start of the largest file there. Like Ping, none of the
+// requests are retried. If a step after the ping fails, the result holds the
+// measurements made so far alongside the error.
+func (c *Client) Probe(ctx context.Context) (*ProbeResult, error) {
+	ping, err := c.Ping(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProbeResult{PingResult: *ping}
+
+	fw, err := c.firmwareForVersion(ping.Version)
+	if err != nil {
+		return result, opError("probe", "/", err)
+	}
+	start := time.Now()
+	listing, err := c.listDirectoryAttempt(ctx, fw, "/", fw.APIPath("/"))
+	if err != nil {
+		return result, opError("probe", "/", err)
+	}
+	result.ListingLatency = time.Since(start)
+
+	var file *Entry
+	for _, entry := range listing.Entries {
+		if !entry.IsDir && entry.Size >= probeMinFileSize && (file == nil || entry.Size > file.Size) {
+			file = entry
+		}
+	}
+	if file == nil {
+		return result, nil
+	}
+
+	result.File = file.Path
+	start = time.Now()
+	n, err := c.readPrefix(ctx, file, probeReadSize)
+	result.BytesRead = n
+	result.ReadDuration = time.Since(start)
+	if result.ReadDuration > 0 {
+		result.BytesPerSecond = float64(n) / result.ReadDuration.Seconds()
+	}
+	if err != nil {
+		return result, opError("probe", file.Path, err)
+	}
+	return result, nil
+}
+
+// readPrefix reads and discards up to size bytes from the start of a file, and returns
+// the number of bytes read. Files shorter than size are read entirely.
+func (c *Client) readPrefix(ctx context.Context, entry *Entry, size int64) (int64, error) {
+	req, err := http.NewRequest("GET", entry.URL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", size-1))
+
+	resp, err := c.doRequest(ctx, "download", req)
+	if err != nil {
+		return 0, fmt.Errorf("range request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return 0, statusError("download", resp)
+	}
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, size))
+	if err != nil {
+		return n, fmt.Errorf("failed to read file: %w", err)
+	}
+	return n, nil
+}
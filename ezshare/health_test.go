@@ -0,0 +1,160 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPing(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"STR.edf": "data"})
+
+	result, err := client.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if result.Version.ChipModel != "LZ1801EDPG" {
+		t.Errorf("expected chip LZ1801EDPG, got %s", result.Version.ChipModel)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("expected a positive latency, got %v", result.Latency)
+	}
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	client := createTestClient(t, "http://"+addr, WithRetries(3))
+	start := time.Now()
+	_, err = client.Ping(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for an unreachable card")
+	}
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != "ping" {
+		t.Errorf("expected a ping OpError, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Ping should not retry, took %v", time.Since(start))
+	}
+}
+
+func TestProbe(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"STR.edf":                  strings.Repeat("s", 100*1024),
+		"Identification.tgt":       "id",
+		"DATALOG/20260104/BRP.edf": strings.Repeat("b", 500*1024),
+	})
+
+	result, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if result.Version == nil || result.Latency <= 0 || result.ListingLatency <= 0 {
+		t.Errorf("missing measurements: %+v", result)
+	}
+	if result.File != "/STR.edf" {
+		t.Errorf("expected to read /STR.edf, got %q", result.File)
+	}
+	if result.BytesRead != probeReadSize {
+		t.Errorf("expected %d bytes read, got %d", probeReadSize, result.BytesRead)
+	}
+	if result.BytesPerSecond <= 0 {
+		t.Errorf("expected a positive throughput, got %v", result.BytesPerSecond)
+	}
+}
+
+func TestProbe_SmallFile(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"Identification.tgt": "id",
+		"STR.edf":            strings.Repeat("s", 20*1024),
+	})
+
+	result, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if result.File != "/STR.edf" || result.BytesRead != 20*1024 {
+		t.Errorf("expected 20 KiB of /STR.edf, got %d of %q", result.BytesRead, result.File)
+	}
+}
+
+func TestProbe_OnlyTinyFiles(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"Identification.tgt": "id"})
+
+	result, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if result.ListingLatency <= 0 || result.File != "" || result.BytesRead != 0 {
+		t.Errorf("expected a listing but no throughput measurement, got %+v", result)
+	}
+}
+
+func TestProbe_NoFiles(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"DATALOG/BRP.edf": "data"})
+
+	result, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if result.File != "" || result.BytesRead != 0 {
+		t.Errorf("expected no throughput measurement, got %+v", result)
+	}
+}
+
+func TestProbe_SingleVersionRequest(t *testing.T) {
+	server, _ := setupTestCard(t, map[string]string{"STR.edf": "data"})
+	var versionRequests atomic.Int32
+	base := server.Client().Transport
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("command") == "version" {
+			versionRequests.Add(1)
+		}
+		return base.RoundTrip(req)
+	})
+	client := createTestClient(t, server.URL,
+		WithHTTPClient(&http.Client{Transport: transport}), WithDeviceLocation(time.UTC))
+
+	if _, err := client.Probe(context.Background()); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if n := versionRequests.Load(); n != 1 {
+		t.Errorf("expected the firmware to come from the ping's version, got %d version requests", n)
+	}
+}
+
+// apiPathFirmware records the paths converted by the wrapped driver.
+type apiPathFirmware struct {
+	Firmware
+	paths []string
+}
+
+func (f *apiPathFirmware) APIPath(unixPath string) string {
+	f.paths = append(f.paths, unixPath)
+	return f.Firmware.APIPath(unixPath)
+}
+
+func TestProbe_UsesFirmwareDriver(t *testing.T) {
+	server, _ := setupTestCard(t, map[string]string{"STR.edf": "data"})
+	fw := &apiPathFirmware{Firmware: FirmwareLZ1801EDPG}
+	client := createTestClient(t, server.URL, WithHTTPClient(server.Client()), WithFirmware(fw))
+
+	if _, err := client.Probe(context.Background()); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !slices.Contains(fw.paths, "/") {
+		t.Errorf("expected the root listing to go through the driver, got paths %v", fw.paths)
+	}
+}
@@ -2,9 +2,12 @@ package ezshare
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,9 +17,11 @@ import (
 )
 
 var (
-	timestampRegex  = regexp.MustCompile(`(\d{4})-\s*(\d{1,2})-\s*(\d{1,2})\s+(\d{1,2}):\s*(\d{1,2}):\s*(\d{1,2})`)
-	sizeRegex       = regexp.MustCompile(`(\d+)KB|&lt;DIR&gt;|<DIR>`)
-	whitespaceRegex = regexp.MustCompile(`\s+`)
+	timestampRegex    = regexp.MustCompile(`(\d{4})-\s*(\d{1,2})-\s*(\d{1,2})\s+(\d{1,2}):\s*(\d{1,2}):\s*(\d{1,2})`)
+	sizeRegex         = regexp.MustCompile(`(\d+)KB|&lt;DIR&gt;|<DIR>`)
+	whitespaceRegex   = regexp.MustCompile(`\s+`)
+	totalEntriesRegex = regexp.MustCompile(`Total Entries:\s*(\d+)`)
+	totalSizeRegex    = regexp.MustCompile(`Total Size:\s*(\d+)KB`)
 )
 
 type rawEntry struct {
@@ -25,49 +30,116 @@ type rawEntry struct {
 	name string
 }
 
-// ListDirectory returns the contents of a directory on the device.
+// ListDirectory returns the contents of a directory on the device. Path components may
+// be given as long names or as 8.3 names.
 func (c *Client) ListDirectory(ctx context.Context, dirPath string) ([]*Entry, error) {
-	var entries []*Entry
-	err := c.retryOperation(ctx, func() error {
-		result, err := c.listDirectoryAttempt(ctx, dirPath)
+	listing, err := c.List(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	return listing.Entries, nil
+}
+
+// List is like ListDirectory, but also returns the listing footer totals and, with
+// WithLenientListings, the lines that were skipped because they could not be parsed.
+func (c *Client) List(ctx context.Context, dirPath string) (*Listing, error) {
+	apiPath, err := c.resolveAPIPath(ctx, dirPath)
+	if err != nil {
+		return nil, opError("list", cleanPath(dirPath), err)
+	}
+	return c.listDirectory(ctx, dirPath, apiPath)
+}
+
+// ListEntry lists a directory entry obtained from an earlier listing. Unlike ListDirectory,
+// it uses the 8.3 path from the entry, so long names need not be resolved again.
+func (c *Client) ListEntry(ctx context.Context, dir *Entry) (*Listing, error) {
+	if !dir.IsDir {
+		return nil, opError("list", entryPath(dir), errors.New("not a directory"))
+	}
+	if dir.RemotePath == "" {
+		return c.List(ctx, entryPath(dir))
+	}
+	return c.listDirectory(ctx, entryPath(dir), dir.RemotePath)
+}
+
+// listDirectory lists a directory whose DOS path is already known.
+func (c *Client) listDirectory(ctx context.Context, dirPath, apiPath string) (*Listing, error) {
+	fw, err := c.DetectFirmware(ctx)
+	if err != nil {
+		return nil, opError("list", cleanPath(dirPath), err)
+	}
+
+	var listing *Listing
+	err = c.retryOperation(ctx, func() error {
+		result, err := c.listDirectoryAttempt(ctx, fw, dirPath, apiPath)
 		if err == nil {
-			entries = result
+			listing = result
 		}
 		return err
 	})
-	return entries, err
+	if err != nil {
+		return nil, opError("list", cleanPath(dirPath), err)
+	}
+	return listing, nil
 }
 
-func (c *Client) listDirectoryAttempt(ctx context.Context, dirPath string) ([]*Entry, error) {
-	apiPath := convertUnixPathToAPI(dirPath)
-	listURL := c.buildURL("/dir", "dir", apiPath)
+func (c *Client) listDirectoryAttempt(ctx context.Context, fw Firmware, dirPath, apiPath string) (*Listing, error) {
+	listURL := fw.ListURL(c.baseURL, apiPath)
 
 	req, err := http.NewRequest("GET", listURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, req)
+	resp, err := c.doRequest(ctx, "list", req)
 	if err != nil {
 		return nil, fmt.Errorf("directory listing request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("%w: %s", ErrNotFound, dirPath)
-	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, statusError("list", resp)
 	}
 
-	entries, err := parseDirectoryListing(resp.Body)
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Type"))
 	if err != nil {
+		return nil, err
+	}
+	listing, err := fw.ParseListing(body)
+	if err == nil && len(listing.Warnings) > 0 && !c.lenientListings {
+		err = fmt.Errorf("failed to parse entry: %w", listing.Warnings[0])
+	}
+	if err != nil {
+		c.logAttrs(ctx, slog.LevelWarn, "malformed directory listing",
+			slog.String("path", dirPath),
+			slog.Any("error", err))
 		return nil, fmt.Errorf("failed to parse directory listing: %w", err)
 	}
-	return entries, nil
+
+	for _, warning := range listing.Warnings {
+		if c.logger != nil {
+			c.logger.Printf("Skipping malformed listing line in %s: %v", dirPath, warning)
+		}
+		c.logAttrs(ctx, slog.LevelWarn, "skipping malformed listing line",
+			slog.String("path", dirPath),
+			slog.String("line", warning.Line),
+			slog.String("reason", warning.Reason))
+	}
+
+	parent := cleanPath(dirPath)
+	for _, entry := range listing.Entries {
+		entry.Path = path.Join(parent, entry.Name)
+		entry.URL = c.resolveHref(req.URL, entry.URL)
+		entry.Timestamp = deviceTime(entry.Timestamp, c.location)
+	}
+	return listing, nil
 }
 
-func parseDirectoryListing(r io.Reader) ([]*Entry, error) {
+// parseDirectoryListing parses an LZ1801EDPG listing. Bad lines are collected as warnings.
+// The footer is the last thing the card sends, so a listing without one was truncated.
+// Its totals are not checked against the entries: the card's counts don't reliably match
+// the lines it sends.
+func parseDirectoryListing(r io.Reader) (*Listing, error) {
 	doc, err := html.Parse(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
@@ -75,23 +147,54 @@ func parseDirectoryListing(r io.Reader) ([]*Entry, error) {
 
 	preNode := findPreTag(doc)
 	if preNode == nil {
-		return nil, fmt.Errorf("%w: <pre> tag not found", ErrInvalidResponse)
+		return nil, &ParseError{Reason: "<pre> tag not found"}
 	}
 
-	rawEntries := extractRawEntries(preNode)
+	rawEntries, footer := extractRawEntries(preNode)
 
-	var entries []*Entry
+	listing := &Listing{}
 	for _, raw := range rawEntries {
 		entry, err := parseEntry(raw)
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			listing.Warnings = append(listing.Warnings, parseErr)
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse entry: %w", err)
 		}
 		if entry != nil {
-			entries = append(entries, entry)
+			listing.Entries = append(listing.Entries, entry)
 		}
 	}
 
-	return entries, nil
+	if err := parseFooter(listing, footer); err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// parseFooter fills in the listing totals from the "Total Entries" and "Total Size" lines
+// that end every complete listing.
+func parseFooter(listing *Listing, footer string) error {
+	entriesMatch := totalEntriesRegex.FindStringSubmatch(footer)
+	sizeMatch := totalSizeRegex.FindStringSubmatch(footer)
+	if entriesMatch == nil || sizeMatch == nil {
+		return fmt.Errorf("%w: footer not found", ErrIncompleteListing)
+	}
+
+	totalEntries, err := strconv.Atoi(entriesMatch[1])
+	if err != nil {
+		return &ParseError{Line: entriesMatch[0], Reason: "invalid entry count", Err: err}
+	}
+	totalSizeKB, err := strconv.ParseInt(sizeMatch[1], 10, 64)
+	if err != nil {
+		return &ParseError{Line: sizeMatch[0], Reason: "invalid total size", Err: err}
+	}
+	listing.TotalEntries = totalEntries
+	listing.TotalSize = totalSizeKB * 1024
+	return nil
 }
 
 func findPreTag(n *html.Node) *html.Node {
@@ -106,7 +209,9 @@ func findPreTag(n *html.Node) *html.Node {
 	return nil
 }
 
-func extractRawEntries(preNode *html.Node) []rawEntry {
+// extractRawEntries returns the linked lines of a listing, and the text that follows the
+// last link, which holds the footer.
+func extractRawEntries(preNode *html.Node) ([]rawEntry, string) {
 	var entries []rawEntry
 	var currentText strings.Builder
 
@@ -136,7 +241,7 @@ func extractRawEntries(preNode *html.Node) []rawEntry {
 		}
 	}
 
-	return entries
+	return entries, currentText.String()
 }
 
 func parseEntry(raw rawEntry) (*Entry, error) {
@@ -151,19 +256,19 @@ func parseEntry(raw rawEntry) (*Entry, error) {
 
 	timestampMatch := timestampRegex.FindStringSubmatch(text)
 	if timestampMatch == nil {
-		return nil, fmt.Errorf("timestamp not found in line: %q", text)
+		return nil, &ParseError{Line: text, Reason: "timestamp not found"}
 	}
 	normalized := whitespaceRegex.ReplaceAllString(timestampMatch[0], " ")
 	normalized = strings.ReplaceAll(normalized, "- ", "-")
 	normalized = strings.ReplaceAll(normalized, ": ", ":")
 	timestamp, err := time.Parse("2006-1-2 15:4:5", normalized)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse timestamp %q: %w", timestampMatch[0], err)
+		return nil, &ParseError{Line: text, Reason: "invalid timestamp", Err: err}
 	}
 
 	sizeMatch := sizeRegex.FindStringSubmatch(text)
 	if sizeMatch == nil {
-		return nil, fmt.Errorf("size not found in line: %q", text)
+		return nil, &ParseError{Line: text, Reason: "size not found"}
 	}
 	isDir := false
 	var size int64 = 0
@@ -172,18 +277,23 @@ func parseEntry(raw rawEntry) (*Entry, error) {
 	} else if sizeMatch[1] != "" {
 		sizeKB, err := strconv.ParseInt(sizeMatch[1], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse size %q: %w", sizeMatch[1], err)
+			return nil, &ParseError{Line: text, Reason: "invalid size", Err: err}
 		}
 		size = sizeKB * 1024
 	} else {
-		return nil, fmt.Errorf("unexpected size format: %q", sizeMatch[0])
+		return nil, &ParseError{Line: text, Reason: "unexpected size format"}
 	}
 
+	href := encodeHref(raw.href)
+	shortName, remotePath := parseHref(href)
+
 	return &Entry{
-		Name:      raw.name,
-		IsDir:     isDir,
-		Timestamp: timestamp,
-		Size:      size,
-		URL:       raw.href,
+		Name:       raw.name,
+		IsDir:      isDir,
+		Timestamp:  timestamp,
+		Size:       size,
+		URL:        href,
+		ShortName:  shortName,
+		RemotePath: remotePath,
 	}, nil
 }
@@ -1,7 +1,15 @@
 package ezshare
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -29,10 +37,11 @@ Total Size: 87KB
 </body>
 </html>`
 
-	entries, err := parseDirectoryListing(strings.NewReader(html))
+	listing, err := parseDirectoryListing(strings.NewReader(html))
 	if err != nil {
 		t.Fatalf("parseDirectoryListing failed: %v", err)
 	}
+	entries := listing.Entries
 
 	// Should have 4 entries (excluding . and ..)
 	if len(entries) != 4 {
@@ -98,10 +107,11 @@ Total Size: 1919KB
 </body>
 </html>`
 
-	entries, err := parseDirectoryListing(strings.NewReader(html))
+	listing, err := parseDirectoryListing(strings.NewReader(html))
 	if err != nil {
 		t.Fatalf("parseDirectoryListing failed: %v", err)
 	}
+	entries := listing.Entries
 
 	// Should exclude . and .. entries
 	if len(entries) != 2 {
@@ -145,13 +155,221 @@ Total Size: 0KB
 </body>
 </html>`
 
-	entries, err := parseDirectoryListing(strings.NewReader(html))
+	listing, err := parseDirectoryListing(strings.NewReader(html))
 	if err != nil {
 		t.Fatalf("parseDirectoryListing failed: %v", err)
 	}
+	entries := listing.Entries
 
 	// Should be empty (. and .. are excluded)
 	if len(entries) != 0 {
 		t.Errorf("expected 0 entries, got %d", len(entries))
 	}
 }
+
+// listingHTML wraps lines and a footer in the card's listing page.
+func listingHTML(lines, footer string) string {
+	return "<html><body><pre>\n" + lines + "\n" + footer + "</pre>\n</body>\n</html>"
+}
+
+const (
+	goodLine1 = `   2026- 1- 4   10:55:58          64KB  <a href="http://192.168.4.1/download?file=JOURNAL.DAT"> Journal.dat</a>
+`
+	goodLine2 = `   2026- 1- 5   12:10: 0          22KB  <a href="http://192.168.4.1/download?file=STR.EDF"> STR.edf</a>
+`
+	badLine = `   2026-13-45   garbage          ??KB  <a href="http://192.168.4.1/download?file=BAD.EDF"> BAD.edf</a>
+`
+)
+
+func TestParseDirectoryListing_Footer(t *testing.T) {
+	listing, err := parseDirectoryListing(strings.NewReader(listingHTML(goodLine1+goodLine2, "Total Entries: 2\nTotal Size: 86KB\n")))
+	if err != nil {
+		t.Fatalf("parseDirectoryListing failed: %v", err)
+	}
+	if listing.TotalEntries != 2 || listing.TotalSize != 86*1024 {
+		t.Errorf("totals = %d entries, %d bytes", listing.TotalEntries, listing.TotalSize)
+	}
+	if len(listing.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", listing.Warnings)
+	}
+}
+
+func TestParseDirectoryListing_FooterTotalsNotChecked(t *testing.T) {
+	// As in the card's own listings, the totals don't match the lines sent
+	listing, err := parseDirectoryListing(strings.NewReader(listingHTML(goodLine1+goodLine2, "Total Entries: 7\nTotal Size: 88KB\n")))
+	if err != nil {
+		t.Fatalf("parseDirectoryListing failed: %v", err)
+	}
+	if len(listing.Entries) != 2 || listing.TotalEntries != 7 || listing.TotalSize != 88*1024 {
+		t.Errorf("got %d entries, totals %d entries, %d bytes", len(listing.Entries), listing.TotalEntries, listing.TotalSize)
+	}
+}
+
+func TestParseDirectoryListing_Warnings(t *testing.T) {
+	listing, err := parseDirectoryListing(strings.NewReader(listingHTML(goodLine1+badLine+goodLine2, "Total Entries: 3\nTotal Size: 100KB\n")))
+	if err != nil {
+		t.Fatalf("parseDirectoryListing failed: %v", err)
+	}
+	if len(listing.Entries) != 2 || listing.Entries[0].Name != "Journal.dat" || listing.Entries[1].Name != "STR.edf" {
+		t.Errorf("expected the two good entries, got %v", listing.Entries)
+	}
+	if len(listing.Warnings) != 1 || !strings.Contains(listing.Warnings[0].Line, "garbage") {
+		t.Errorf("expected one warning for the bad line, got %v", listing.Warnings)
+	}
+}
+
+func TestParseDirectoryListing_Incomplete(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+	}{
+		{"truncated", "<html><body><pre>\n" + goodLine1 + goodLine2[:30]},
+		{"no footer", listingHTML(goodLine1+goodLine2, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDirectoryListing(strings.NewReader(tt.html))
+			if !errors.Is(err, ErrIncompleteListing) {
+				t.Errorf("expected ErrIncompleteListing, got %v", err)
+			}
+			if !IsRetryable(err) {
+				t.Error("incomplete listings should be retried")
+			}
+		})
+	}
+}
+
+func TestList_LenientListings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, listingHTML(goodLine1+badLine+goodLine2, "Total Entries: 3\nTotal Size: 100KB\n"))
+	}))
+	defer server.Close()
+
+	strict := createTestClient(t, server.URL, WithFirmware(FirmwareLZ1801EDPG))
+	var parseErr *ParseError
+	if _, err := strict.List(context.Background(), "/"); !errors.As(err, &parseErr) {
+		t.Errorf("strict listing: expected ParseError, got %v", err)
+	}
+
+	lenient := createTestClient(t, server.URL, WithFirmware(FirmwareLZ1801EDPG), WithLenientListings())
+	listing, err := lenient.List(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("lenient listing failed: %v", err)
+	}
+	if len(listing.Entries) != 2 || len(listing.Warnings) != 1 {
+		t.Errorf("expected 2 entries and 1 warning, got %d and %d", len(listing.Entries), len(listing.Warnings))
+	}
+	if listing.Entries[1].Path != "/STR.edf" {
+		t.Errorf("Path = %q, want /STR.edf", listing.Entries[1].Path)
+	}
+}
+
+func TestList_RetriesTruncatedListing(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str", "/DATALOG/BRP.edf": "brp"})
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dir" && requests.Add(1) == 1 {
+			// Cut the listing short, as a flaky connection does
+			rec := httptest.NewRecorder()
+			card.Handler.ServeHTTP(rec, r)
+			body := rec.Body.Bytes()
+			_, _ = w.Write(body[:bytes.Index(body, []byte("Total Entries"))])
+			return
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(3)))
+	listing, err := client.List(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listing.Entries) != 2 || listing.TotalEntries != 2 {
+		t.Errorf("expected 2 entries, got %d (footer %d)", len(listing.Entries), listing.TotalEntries)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected the truncated listing to be retried once, got %d requests", requests.Load())
+	}
+}
+
+func TestListDirectory_RewritesHrefs(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str", "/DATALOG/BRP.edf": "brp"})
+
+	// A plain HTTP client can't reach 192.168.4.1, so downloads only work if links are rewritten
+	client := createTestClient(t, card.URL, WithDeviceLocation(time.UTC))
+	entries, err := client.ListDirectory(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.URL, card.URL+"/") {
+			t.Errorf("%s: URL %q not rewritten to %s", entry.Name, entry.URL, card.URL)
+		}
+		if entry.IsDir && entry.URL != card.URL+"/dir?dir=A:%5CDATALOG" {
+			t.Errorf("relative directory href resolved to %q", entry.URL)
+		}
+		if !entry.IsDir {
+			downloadAndVerify(t, client, entry, filepath.Join(t.TempDir(), entry.Name), "str")
+		}
+	}
+}
+
+func TestListDirectory_HrefRewritingDisabled(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str", "/DATALOG/BRP.edf": "brp"})
+
+	client := createTestClient(t, card.URL, WithHTTPClient(card.Client()), WithHrefRewriting(false))
+	entries, err := client.ListDirectory(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	for _, entry := range entries {
+		want := "http://192.168.4.1/download?file=STR.EDF"
+		if entry.IsDir {
+			// Relative links are still resolved against the listing URL
+			want = card.URL + "/dir?dir=A:%5CDATALOG"
+		}
+		if entry.URL != want {
+			t.Errorf("%s: URL = %q, want %q", entry.Name, entry.URL, want)
+		}
+	}
+}
+
+func TestListEntry(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"System Volume Information/IndexerVolumeGuid": "guid",
+		"STR.edf": "data",
+	})
+	ctx := context.Background()
+
+	entries, err := client.ListDirectory(ctx, "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	var dir, file *Entry
+	for _, entry := range entries {
+		if entry.IsDir {
+			dir = entry
+		} else {
+			file = entry
+		}
+	}
+	if dir == nil || file == nil {
+		t.Fatalf("expected a directory and a file, got %d entries", len(entries))
+	}
+
+	listing, err := client.ListEntry(ctx, dir)
+	if err != nil {
+		t.Fatalf("ListEntry failed: %v", err)
+	}
+	if len(listing.Entries) != 1 || listing.Entries[0].Path != "/System Volume Information/IndexerVolumeGuid" {
+		t.Errorf("unexpected entries: %+v", listing.Entries)
+	}
+
+	if _, err := client.ListEntry(ctx, file); err == nil {
+		t.Error("expected an error listing a file")
+	}
+}
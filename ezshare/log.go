@@ -0,0 +1,19 @@
+package ezshare
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logAttrs emits a structured record to the logger set with WithSlog, if any.
+func (c *Client) logAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.slog == nil {
+		return
+	}
+	c.slog.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// entryAttr identifies the file a record is about.
+func entryAttr(entry *Entry) slog.Attr {
+	return slog.String("path", entryPath(entry))
+}
@@ -0,0 +1,86 @@
+package ezshare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureSlog returns a debug-level JSON logger and a function decoding what it logged.
+func captureSlog(t *testing.T) (*slog.Logger, func() []map[string]any) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return logger, func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("invalid log line %q: %v", line, err)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func findRecord(records []map[string]any, msg string) map[string]any {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestSlog_Download(t *testing.T) {
+	content := strings.Repeat("0123456789", 500)
+	server, _ := flakyServer(t, content, 1500)
+	defer server.Close()
+
+	logger, records := captureSlog(t)
+	entry := &Entry{Name: "flaky.txt", Path: "/DATALOG/flaky.txt", URL: server.URL + "/download?file=flaky.txt", Size: 5120}
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(3)), WithSlog(logger))
+	downloadAndVerify(t, client, entry, filepath.Join(t.TempDir(), "flaky.txt"), content)
+
+	logged := records()
+	request := findRecord(logged, "request")
+	if request == nil || request["level"] != "DEBUG" || request["status"] != float64(200) || request["duration"] == nil {
+		t.Errorf("request record = %v", request)
+	}
+	resume := findRecord(logged, "connection lost, resuming download")
+	if resume == nil || resume["level"] != "WARN" || resume["path"] != "/DATALOG/flaky.txt" || resume["bytes"] != float64(1500) {
+		t.Errorf("resume record = %v", resume)
+	}
+	done := findRecord(logged, "download complete")
+	if done == nil || done["bytes"] != float64(len(content)) {
+		t.Errorf("download complete record = %v", done)
+	}
+}
+
+func TestSlog_Retry(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	logger, records := captureSlog(t)
+	client := createTestClient(t, card.URL, WithHTTPClient(card.Client()),
+		WithRetryPolicy(fastRetryPolicy(2)), WithSlog(logger))
+	if _, err := client.ListDirectory(context.Background(), "/MISSING"); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+	if retry := findRecord(records(), "retrying operation"); retry != nil {
+		t.Errorf("not-found errors should not be retried: %v", retry)
+	}
+
+	card.Close()
+	if _, err := client.ListDirectory(context.Background(), "/"); err == nil {
+		t.Fatal("expected error with the card offline")
+	}
+	retry := findRecord(records(), "retrying operation")
+	if retry == nil || retry["attempt"] != float64(1) || retry["error"] == nil {
+		t.Errorf("retry record = %v", retry)
+	}
+}
@@ -1,6 +1,7 @@
 package ezshare
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -29,7 +30,8 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-// WithRetries sets the maximum number of retry attempts for failed requests.
+// WithRetries sets the maximum number of retry attempts for failed requests. It has no
+// effect if a custom policy is set with WithRetryPolicy.
 func WithRetries(maxRetries int) Option {
 	return func(c *Client) {
 		c.maxRetries = maxRetries
@@ -49,3 +51,77 @@ func WithLogger(logger Logger) Option {
 		c.logger = logger
 	}
 }
+
+// WithSlog sets a structured logger. Requests and completed downloads are logged at debug
+// level, resumed downloads and range fallbacks at info, and retries, dropped connections,
+// and malformed listings at warn. Records carry attributes such as path, url, attempt,
+// status, bytes, and duration. It can be combined with WithLogger.
+func WithSlog(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.slog = logger
+	}
+}
+
+// WithLenientListings makes directory listings skip lines that cannot be parsed instead of
+// failing. Skipped lines are logged and returned in Listing.Warnings by List.
+func WithLenientListings() Option {
+	return func(c *Client) {
+		c.lenientListings = true
+	}
+}
+
+// WithDeviceLocation sets the time zone of the device's clock, which listing timestamps are
+// in. The default is time.Local. Around daylight saving time transitions, a repeated
+// wall-clock time resolves to its first occurrence, and a skipped one is moved forward by
+// the length of the gap.
+func WithDeviceLocation(loc *time.Location) Option {
+	return func(c *Client) {
+		c.location = loc
+	}
+}
+
+// WithHrefRewriting controls whether the scheme, host, and port of links in listings are
+// replaced by those of the client's base URL, which is the default. The card links to
+// itself as 192.168.4.1, which is wrong when it is reached through port forwarding, a
+// reverse proxy, or another address. Relative links are resolved either way.
+func WithHrefRewriting(enabled bool) Option {
+	return func(c *Client) {
+		c.rewriteHrefs = enabled
+	}
+}
+
+// WithParallelRanges downloads files of at least 2*minChunkSize bytes as up to n concurrent
+// range requests of at least minChunkSize bytes each. Each range is retried on its own. If the
+// device does not honor range requests, downloads fall back to a single stream.
+func WithParallelRanges(n int, minChunkSize int64) Option {
+	return func(c *Client) {
+		c.parallelRanges = n
+		c.minChunkSize = minChunkSize
+	}
+}
+
+// WithFirmware selects the firmware driver explicitly, instead of detecting it from the
+// device's version information.
+func WithFirmware(fw Firmware) Option {
+	return func(c *Client) {
+		c.firmware = fw
+	}
+}
+
+// WithRetryPolicy sets the policy that decides whether and when failed requests are retried.
+// See BackoffPolicy for the default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithProgress sets a callback that receives progress events while files are downloaded with
+// DownloadFile or read from GetFile. Events for one transfer are sent at most every 100ms,
+// and the last one has Done set. Transfers run concurrently call fn concurrently, so it must be
+// safe for concurrent use and should return quickly.
+func WithProgress(fn func(ProgressEvent)) Option {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
@@ -0,0 +1,126 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// errRangesNotSupported is returned by parallel downloads when the device answers a
+// range request with the whole file, so the download must fall back to a single stream.
+var errRangesNotSupported = errors.New("device does not support range requests")
+
+// useParallelRanges reports whether entry is large enough to be split into ranges.
+func (c *Client) useParallelRanges(entry *Entry) bool {
+	return c.parallelRanges > 1 && entry.Size >= 2*c.minChunkSize
+}
+
+// downloadParallel downloads a file as several concurrent range requests written into
+// the destination with WriteAt. Each range is retried independently, continuing from
+// the last byte it received.
+func (c *Client) downloadParallel(ctx context.Context, entry *Entry, destPath string) (err error) {
+	var totalSize int64
+	err = c.retryOperation(ctx, func() error {
+		var err error
+		totalSize, err = c.probeRangeSupport(ctx, entry)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	chunkSize := max(c.minChunkSize, (totalSize+int64(c.parallelRanges)-1)/int64(c.parallelRanges))
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", closeErr)
+		}
+		if err != nil {
+			// A partially written file has holes, so it cannot be resumed later
+			_ = os.Remove(destPath)
+		}
+	}()
+	if err := out.Truncate(totalSize); err != nil {
+		return fmt.Errorf("failed to allocate destination file: %w", err)
+	}
+
+	progress := c.newProgress(entry, 0, totalSize)
+	defer progress.finish()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for start := int64(0); start < totalSize; start += chunkSize {
+		end := min(start+chunkSize, totalSize) - 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.downloadRange(ctx, entry, out, start, end, progress); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// probeRangeSupport requests the first byte of a file, and returns the file's exact size
+// if the device honors range requests.
+func (c *Client) probeRangeSupport(ctx context.Context, entry *Entry) (int64, error) {
+	resp, err := c.boundedRangeRequest(ctx, entry, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, errRangesNotSupported
+	}
+	return parseContentRangeTotal(resp.Header.Get("Content-Range"))
+}
+
+// downloadRange writes bytes start through end (inclusive) of a file into out at the
+// same offsets, retrying from the last byte received on failure.
+func (c *Client) downloadRange(ctx context.Context, entry *Entry, out io.WriterAt, start, end int64, progress *progressTracker) error {
+	offset := start
+	return c.retryOperation(ctx, func() error {
+		resp, err := c.boundedRangeRequest(ctx, entry, offset, end)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusPartialContent {
+			return errRangesNotSupported
+		}
+
+		written, err := io.Copy(io.NewOffsetWriter(out, offset), &progressReader{r: resp.Body, progress: progress})
+		offset += written
+		if err != nil {
+			return fmt.Errorf("failed to write range %d-%d: %w", start, end, err)
+		}
+		if offset != end+1 {
+			return fmt.Errorf("%w: range %d-%d ended at byte %d", ErrInvalidResponse, start, end, offset)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,165 @@
+package ezshare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDownloadFile_ParallelRanges(t *testing.T) {
+	content := edfContent(1918 * 1024)
+	card, _ := setupTestCard(t, map[string]string{"/BRP.edf": content})
+
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	client := createTestClient(t, server.URL, WithParallelRanges(4, 256*1024))
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	downloadAndVerify(t, client, entry, destPath, content)
+
+	// One probe plus four ranges
+	if len(ranges) != 5 {
+		t.Errorf("expected 5 requests, got %d: %v", len(ranges), ranges)
+	}
+	for _, r := range ranges {
+		if !strings.HasPrefix(r, "bytes=") {
+			t.Errorf("expected range request, got %q", r)
+		}
+	}
+}
+
+func TestDownloadFile_ParallelRanges_RetriesSingleRange(t *testing.T) {
+	content := edfContent(1024 * 1024)
+	card, _ := setupTestCard(t, map[string]string{"/BRP.edf": content})
+
+	var mu sync.Mutex
+	failed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" && strings.HasPrefix(r.Header.Get("Range"), "bytes=524288-") {
+			mu.Lock()
+			first := !failed
+			failed = true
+			mu.Unlock()
+			if first {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	client := createTestClient(t, server.URL, WithParallelRanges(2, 256*1024))
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	downloadAndVerify(t, client, entry, destPath, content)
+	if !failed {
+		t.Error("expected the second range to fail once")
+	}
+}
+
+func TestDownloadFile_ParallelRanges_FallbackOn200(t *testing.T) {
+	content := strings.Repeat("No range support. ", 50000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt", Size: int64(len(content))}
+	client := createTestClient(t, server.URL, WithParallelRanges(4, 64*1024))
+	destPath := filepath.Join(t.TempDir(), "fallback.txt")
+
+	downloadAndVerify(t, client, entry, destPath, content)
+}
+
+func TestDownloadFile_ParallelRanges_RemovesFileOnFailure(t *testing.T) {
+	content := edfContent(512 * 1024)
+	card, _ := setupTestCard(t, map[string]string{"/BRP.edf": content})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" && r.Header.Get("Range") != "bytes=0-0" {
+			http.NotFound(w, r)
+			return
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	client := createTestClient(t, server.URL, WithParallelRanges(2, 128*1024))
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	if err := client.DownloadFile(context.Background(), entry, destPath); err == nil {
+		t.Fatal("expected download to fail")
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Errorf("expected partial file to be removed, got %v", err)
+	}
+}
+
+func TestDownloadFile_ParallelRanges_ResumesPartialFile(t *testing.T) {
+	content := edfContent(1024 * 1024)
+	card, _ := setupTestCard(t, map[string]string{"/BRP.edf": content})
+
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	client := createTestClient(t, server.URL, WithParallelRanges(4, 128*1024))
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	// A partial copy left behind by an earlier run
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	if err := os.WriteFile(destPath, []byte(content[:300*1024]), 0644); err != nil {
+		t.Fatalf("Failed to write partial file: %v", err)
+	}
+	downloadAndVerify(t, client, entry, destPath, content)
+
+	want := []string{fmt.Sprintf("bytes=%d-", 300*1024)}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("expected a single resuming request %v, got %v", want, ranges)
+	}
+}
@@ -0,0 +1,121 @@
+package ezshare
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum time between progress events for one transfer.
+const progressInterval = 100 * time.Millisecond
+
+// ProgressEvent reports the progress of a file transfer.
+type ProgressEvent struct {
+	// Entry is the file being transferred.
+	Entry *Entry
+	// Transferred is the number of bytes of the file received so far, including ResumeOffset.
+	Transferred int64
+	// Total is the size of the file in bytes, or -1 if the device did not report it.
+	Total int64
+	// ResumeOffset is the byte the transfer started from when it resumed a partial file.
+	ResumeOffset int64
+	// BytesPerSecond is the average throughput of this transfer since it started.
+	BytesPerSecond float64
+	// Done is set on the last event of a transfer, whether it completed or failed.
+	Done bool
+}
+
+// progressTracker counts the bytes of one transfer and reports them to the client's
+// progress callback at most every progressInterval. A nil tracker does nothing.
+type progressTracker struct {
+	fn           func(ProgressEvent)
+	entry        *Entry
+	total        int64
+	resumeOffset int64
+	start        time.Time
+
+	mu          sync.Mutex
+	transferred int64
+	lastReport  time.Time
+	done        bool
+}
+
+// newProgress starts tracking a transfer of entry from resumeOffset, or returns nil if no
+// progress callback is configured.
+func (c *Client) newProgress(entry *Entry, resumeOffset, total int64) *progressTracker {
+	if c.progress == nil {
+		return nil
+	}
+	p := &progressTracker{
+		fn:           c.progress,
+		entry:        entry,
+		total:        total,
+		resumeOffset: resumeOffset,
+		start:        time.Now(),
+		transferred:  resumeOffset,
+	}
+	p.report(false)
+	return p
+}
+
+// add records n more bytes received.
+func (p *progressTracker) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transferred += int64(n)
+	if time.Since(p.lastReport) >= progressInterval {
+		p.reportLocked(false)
+	}
+}
+
+// finish sends the final event of the transfer. Subsequent calls do nothing.
+func (p *progressTracker) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reportLocked(true)
+}
+
+func (p *progressTracker) report(done bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reportLocked(done)
+}
+
+func (p *progressTracker) reportLocked(done bool) {
+	if p.done {
+		return
+	}
+	p.done = done
+	p.lastReport = time.Now()
+
+	var rate float64
+	if elapsed := p.lastReport.Sub(p.start).Seconds(); elapsed > 0 {
+		rate = float64(p.transferred-p.resumeOffset) / elapsed
+	}
+	p.fn(ProgressEvent{
+		Entry:          p.entry,
+		Transferred:    p.transferred,
+		Total:          p.total,
+		ResumeOffset:   p.resumeOffset,
+		BytesPerSecond: rate,
+		Done:           done,
+	})
+}
+
+// progressReader counts the bytes read from r.
+type progressReader struct {
+	r        io.Reader
+	progress *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.progress.add(n)
+	return n, err
+}
@@ -0,0 +1,135 @@
+package ezshare
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// progressRecorder collects progress events.
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []ProgressEvent
+}
+
+func (r *progressRecorder) record(ev ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// check verifies that events grow monotonically and end with a single Done event.
+func (r *progressRecorder) check(t *testing.T, wantTotal, wantResume int64) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		t.Fatal("no progress events")
+	}
+	var prev int64
+	for i, ev := range r.events {
+		if ev.Transferred < prev {
+			t.Errorf("event %d: transferred went back from %d to %d", i, prev, ev.Transferred)
+		}
+		prev = ev.Transferred
+		if ev.Done != (i == len(r.events)-1) {
+			t.Errorf("event %d: Done = %v", i, ev.Done)
+		}
+		if ev.Total != wantTotal || ev.ResumeOffset != wantResume {
+			t.Errorf("event %d: total %d resume %d, want %d and %d", i, ev.Total, ev.ResumeOffset, wantTotal, wantResume)
+		}
+	}
+	if last := r.events[len(r.events)-1]; last.Transferred != wantTotal {
+		t.Errorf("last event transferred %d bytes, want %d", last.Transferred, wantTotal)
+	}
+}
+
+func TestProgress_DownloadFile(t *testing.T) {
+	content := strings.Repeat("progress ", 20000)
+	server, entry := setupTestServer(t, content)
+	defer server.Close()
+
+	var rec progressRecorder
+	client := createTestClient(t, server.URL, WithProgress(rec.record))
+	downloadAndVerify(t, client, entry, filepath.Join(t.TempDir(), "p.txt"), content)
+
+	rec.check(t, int64(len(content)), 0)
+	if rec.events[0].Entry != entry {
+		t.Error("event does not reference the downloaded entry")
+	}
+}
+
+func TestProgress_ResumeOffset(t *testing.T) {
+	content := strings.Repeat("resume ", 20000)
+	server, entry := setupTestServer(t, content)
+	defer server.Close()
+
+	var rec progressRecorder
+	client := createTestClient(t, server.URL, WithProgress(rec.record))
+	destPath := filepath.Join(t.TempDir(), "p.txt")
+	createPartialFile(t, destPath, content, 5000)
+	downloadAndVerify(t, client, entry, destPath, content)
+
+	rec.check(t, int64(len(content)), 5000)
+}
+
+func TestProgress_MidStreamResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 500)
+	server, _ := flakyServer(t, content, 1500)
+	defer server.Close()
+
+	var rec progressRecorder
+	entry := &Entry{Name: "flaky.txt", URL: server.URL + "/download?file=flaky.txt", Size: 5120}
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(3)), WithProgress(rec.record))
+	downloadAndVerify(t, client, entry, filepath.Join(t.TempDir(), "flaky.txt"), content)
+
+	rec.check(t, int64(len(content)), 0)
+}
+
+func TestProgress_ParallelRanges(t *testing.T) {
+	content := edfContent(64 * 1024)
+	card, _ := setupTestCard(t, map[string]string{"/BRP.edf": content})
+
+	var rec progressRecorder
+	client := createTestClient(t, card.URL, WithHTTPClient(card.Client()),
+		WithParallelRanges(4, 8*1024), WithProgress(rec.record))
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	downloadAndVerify(t, client, entry, filepath.Join(t.TempDir(), "p.txt"), content)
+
+	rec.check(t, int64(len(content)), 0)
+}
+
+func TestProgress_GetFileClose(t *testing.T) {
+	content := strings.Repeat("partial read ", 1000)
+	server, entry := setupTestServer(t, content)
+	defer server.Close()
+
+	var rec progressRecorder
+	client := createTestClient(t, server.URL, WithProgress(rec.record))
+	reader, err := client.GetFile(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	if _, err := io.ReadFull(reader, make([]byte, 100)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	_ = reader.Close()
+	_ = reader.Close()
+
+	last := rec.events[len(rec.events)-1]
+	if !last.Done || last.Transferred < 100 {
+		t.Errorf("last event = %+v, want Done after at least 100 bytes", last)
+	}
+	for _, ev := range rec.events[:len(rec.events)-1] {
+		if ev.Done {
+			t.Error("Done reported more than once")
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// readAheadSize is the minimum number of bytes fetched by each range request made by
+// FileReader, so that a series of small sequential reads costs a single round trip.
+const readAheadSize = 16 * 1024
+
+// FileReader provides random access to a file on the device using HTTP range
+// requests. It implements io.ReaderAt, io.ReadSeeker, and io.Closer. ReadAt may be
+// called concurrently; Read and Seek share an offset and may not.
+type FileReader struct {
+	ctx    context.Context
+	client *Client
+	entry  *Entry
+	size   int64
+	offset int64
+
+	mu     sync.Mutex
+	buf    []byte
+	bufOff int64
+}
+
+var (
+	_ io.ReaderAt   = (*FileReader)(nil)
+	_ io.ReadSeeker = (*FileReader)(nil)
+)
+
+// OpenReaderAt opens a file for random access. Only the requested byte ranges (plus a
+// small read-ahead) are transferred, which makes it cheap to read file headers without
+// downloading whole files. If entry.ExactSize is not known yet, it is fetched with Head.
+func (c *Client) OpenReaderAt(ctx context.Context, entry *Entry) (*FileReader, error) {
+	if entry.ExactSize == 0 {
+		if err := c.Head(ctx, entry); err != nil {
+			return nil, err
+		}
+	}
+	return &FileReader{
+		ctx:    ctx,
+		client: c,
+		entry:  entry,
+		size:   entry.ExactSize,
+	}, nil
+}
+
+// Size returns the exact size of the file in bytes.
+func (r *FileReader) Size() int64 {
+	return r.size
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *FileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && off < r.size {
+		chunk, err := r.bufferedRange(off, len(p)-n)
+		if err != nil {
+			return n, opError("read", entryPath(r.entry), err)
+		}
+		copied := copy(p[n:], chunk)
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read implements io.Reader.
+func (r *FileReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (r *FileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close releases the read-ahead buffer. There are no open connections between reads.
+func (r *FileReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf = nil
+	return nil
+}
+
+// bufferedRange returns up to length bytes starting at off, from the read-ahead buffer
+// if possible, fetching a new range from the device otherwise.
+func (r *FileReader) bufferedRange(off int64, length int) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if off < r.bufOff || off >= r.bufOff+int64(len(r.buf)) {
+		fetchLen := int64(max(length, readAheadSize))
+		end := min(off+fetchLen, r.size) - 1
+
+		var data []byte
+		err := r.client.retryOperation(r.ctx, func() error {
+			var err error
+			data, err = r.client.fetchRange(r.ctx, r.entry, off, end)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.buf = data
+		r.bufOff = off
+	}
+
+	start := off - r.bufOff
+	return r.buf[start:min(start+int64(length), int64(len(r.buf)))], nil
+}
+
+// fetchRange reads bytes start through end (inclusive) of a file. If the device
+// ignores the Range header, the unwanted parts of the full response are discarded.
+func (c *Client) fetchRange(ctx context.Context, entry *Entry, start, end int64) ([]byte, error) {
+	resp, err := c.boundedRangeRequest(ctx, entry, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		if _, err := io.CopyN(io.Discard, resp.Body, start); err != nil {
+			return nil, fmt.Errorf("failed to skip to offset %d: %w", start, err)
+		}
+	}
+
+	length := end - start + 1
+	data := make([]byte, length)
+	n, err := io.ReadFull(resp.Body, data)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: received %d bytes, expected %d", ErrInvalidResponse, n, length)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read range: %w", err)
+	}
+	return data, nil
+}
+
+// boundedRangeRequest requests bytes start through end (inclusive) of a file. The
+// response is either a 206 with a validated Content-Range, or a 200 if the device
+// ignored the Range header.
+func (c *Client) boundedRangeRequest(ctx context.Context, entry *Entry, start, end int64) (*http.Response, error) {
+	req, err := http.NewRequest("GET", entry.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := c.doRequest(ctx, "download", req)
+	if err != nil {
+		return nil, fmt.Errorf("range request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		expected := fmt.Sprintf("bytes %d-%d/", start, end)
+		contentRange := resp.Header.Get("Content-Range")
+		if !strings.HasPrefix(contentRange, expected) {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("%w: unexpected Content-Range %q (expected %s*)", ErrInvalidResponse, contentRange, expected)
+		}
+		return resp, nil
+	case http.StatusOK:
+		return resp, nil
+	default:
+		_ = resp.Body.Close()
+		return nil, statusError("download", resp)
+	}
+}
@@ -0,0 +1,165 @@
+package ezshare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingCard starts a fake card that counts download requests.
+func countingCard(t *testing.T, files map[string]string) (*Client, *atomic.Int32) {
+	t.Helper()
+	card, _ := setupTestCard(t, files)
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			downloads.Add(1)
+		}
+		card.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	card.Handler.HrefHost = server.Listener.Addr().String()
+
+	return createTestClient(t, server.URL), &downloads
+}
+
+func edfContent(size int) string {
+	var b strings.Builder
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "%08d", i)
+	}
+	return b.String()[:size]
+}
+
+func TestFileReader_ReadAt(t *testing.T) {
+	content := edfContent(100 * 1024)
+	client, downloads := countingCard(t, map[string]string{"/BRP.edf": content})
+
+	entry, err := client.Stat(context.Background(), "/BRP.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	reader, err := client.OpenReaderAt(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if reader.Size() != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", reader.Size(), len(content))
+	}
+
+	before := downloads.Load()
+	header := make([]byte, 256)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if string(header) != content[:256] {
+		t.Errorf("header mismatch")
+	}
+	// The second read is served from the read-ahead buffer
+	if _, err := reader.ReadAt(header, 256); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if string(header) != content[256:512] {
+		t.Errorf("second block mismatch")
+	}
+	if got := downloads.Load() - before; got != 1 {
+		t.Errorf("expected 1 range request, got %d", got)
+	}
+
+	// A read spanning past the read-ahead buffer fetches the rest
+	big := make([]byte, 40*1024)
+	if _, err := reader.ReadAt(big, 1000); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if string(big) != content[1000:1000+len(big)] {
+		t.Errorf("large read mismatch")
+	}
+
+	tail := make([]byte, 100)
+	n, err := reader.ReadAt(tail, int64(len(content)-40))
+	if n != 40 || err != io.EOF {
+		t.Errorf("ReadAt at end = %d, %v; want 40, EOF", n, err)
+	}
+	if string(tail[:n]) != content[len(content)-40:] {
+		t.Errorf("tail mismatch")
+	}
+}
+
+func TestFileReader_SeekAndRead(t *testing.T) {
+	content := edfContent(50 * 1024)
+	client, _ := countingCard(t, map[string]string{"/STR.edf": content})
+
+	entry, err := client.Stat(context.Background(), "/STR.edf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	reader, err := client.OpenReaderAt(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+
+	if _, err := reader.Seek(-1024, io.SeekEnd); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(rest) != content[len(content)-1024:] {
+		t.Errorf("content after seek mismatch: got %d bytes", len(rest))
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	all, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(all) != content {
+		t.Errorf("full content mismatch: got %d bytes", len(all))
+	}
+}
+
+func TestFetchRange_IgnoredRange(t *testing.T) {
+	content := "0123456789abcdef"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL)
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt"}
+
+	data, err := client.fetchRange(context.Background(), entry, 4, 7)
+	if err != nil {
+		t.Fatalf("fetchRange failed: %v", err)
+	}
+	if string(data) != "4567" {
+		t.Errorf("fetchRange = %q, want %q", data, "4567")
+	}
+}
+
+func TestFetchRange_WrongContentRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-3/16")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("0123"))
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL)
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt"}
+
+	if _, err := client.fetchRange(context.Background(), entry, 4, 7); err == nil {
+		t.Error("expected error for mismatched Content-Range")
+	}
+}
@@ -0,0 +1,145 @@
+package ezshare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// resumingReader reads a file body, and when the connection fails mid-stream, transparently
+// reconnects with a Range request starting at the first byte not yet read. Reconnects are
+// governed by the client's retry policy; the failure count resets whenever data arrives.
+type resumingReader struct {
+	ctx    context.Context
+	client *Client
+	entry  *Entry
+	body   io.ReadCloser
+	// offset is the position in the file of the next byte to be read.
+	offset int64
+	// total is the size of the file, or -1 if unknown.
+	total    int64
+	progress *progressTracker
+
+	failures     int
+	failureStart time.Time
+	// pending is an error that arrived together with data, held until the data is delivered.
+	pending error
+}
+
+// newResumingReader wraps the body of a 200 or 206 response that starts at offset.
+func (c *Client) newResumingReader(ctx context.Context, entry *Entry, resp *http.Response, offset int64) *resumingReader {
+	total := int64(-1)
+	if resp.StatusCode == http.StatusPartialContent {
+		if size, err := parseContentRangeTotal(resp.Header.Get("Content-Range")); err == nil {
+			total = size
+		}
+	} else if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	return &resumingReader{
+		ctx:      ctx,
+		client:   c,
+		entry:    entry,
+		body:     resp.Body,
+		offset:   offset,
+		total:    total,
+		progress: c.newProgress(entry, offset, total),
+	}
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		if r.pending != nil {
+			cause := r.pending
+			r.pending = nil
+			if resumeErr := r.resume(cause); resumeErr != nil {
+				r.progress.finish()
+				return 0, resumeErr
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		r.progress.add(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == io.EOF && r.total >= 0 && r.offset < r.total {
+			// The device closed the connection early
+			err = io.ErrUnexpectedEOF
+		}
+		if err == io.EOF {
+			r.progress.finish()
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		if n > 0 {
+			// Deliver the data first, and reconnect on the next Read
+			r.pending = err
+			return n, nil
+		}
+
+		if resumeErr := r.resume(err); resumeErr != nil {
+			r.progress.finish()
+			return 0, resumeErr
+		}
+	}
+}
+
+func (r *resumingReader) Close() error {
+	r.progress.finish()
+	return r.body.Close()
+}
+
+// resume reconnects after cause interrupted the body, or returns the error that ended
+// the attempts.
+func (r *resumingReader) resume(cause error) error {
+	_ = r.body.Close()
+	r.body = http.NoBody
+
+	for {
+		if r.failures == 0 {
+			r.failureStart = time.Now()
+		}
+		r.failures++
+		delay, retry := r.client.retryPolicy.NextDelay(r.failures, time.Since(r.failureStart), cause)
+		if !retry {
+			return opError("download", entryPath(r.entry), fmt.Errorf("failed to resume at byte %d: %w", r.offset, cause))
+		}
+
+		if r.client.logger != nil {
+			r.client.logger.Printf("Connection lost at byte %d of %s, resuming: %v", r.offset, r.entry.Name, cause)
+		}
+		r.client.logAttrs(r.ctx, slog.LevelWarn, "connection lost, resuming download",
+			entryAttr(r.entry),
+			slog.Int64("bytes", r.offset),
+			slog.Int("attempt", r.failures),
+			slog.Any("error", cause))
+		select {
+		case <-time.After(delay):
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		}
+
+		resp, err := r.client.rangeRequest(r.ctx, r.entry, r.offset)
+		if err != nil {
+			cause = err
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			// The device ignored the Range header; skip the bytes already delivered
+			if _, err := io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+				_ = resp.Body.Close()
+				cause = err
+				continue
+			}
+		}
+		r.body = resp.Body
+		return nil
+	}
+}
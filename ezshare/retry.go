@@ -0,0 +1,148 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed request is retried.
+type RetryPolicy interface {
+	// NextDelay is called after the given attempt (starting at 1) failed with err, with
+	// elapsed being the time since the first attempt started. It returns the delay before
+	// the next attempt, or false to give up and return err.
+	NextDelay(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+}
+
+// BackoffPolicy is a RetryPolicy with exponential backoff and jitter. It is the default
+// policy of the client, configured with the number of retries set by WithRetries.
+type BackoffPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	MaxRetries int
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts.
+	MaxDelay time.Duration
+	// Multiplier is the factor by which the delay grows after each retry.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction in either direction (0.25 = ±25%),
+	// so that concurrent requests don't retry in lockstep.
+	Jitter float64
+	// MaxElapsed stops retrying once this much time has passed since the first attempt.
+	// Zero means no limit.
+	MaxElapsed time.Duration
+	// Retryable classifies errors as transient. If nil, IsRetryable is used. To extend the
+	// default classification, wrap IsRetryable.
+	Retryable func(err error) bool
+	// OnRetry, if set, is called before each retry with the failed attempt number, its
+	// error, and the delay before the next attempt.
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+// NewBackoffPolicy returns the default retry policy: up to maxRetries retries, starting
+// with a 500ms delay that doubles on each retry up to 30s, with ±25% jitter.
+func NewBackoffPolicy(maxRetries int) *BackoffPolicy {
+	return &BackoffPolicy{
+		MaxRetries:   maxRetries,
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.25,
+	}
+}
+
+// NextDelay implements RetryPolicy.
+func (p *BackoffPolicy) NextDelay(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	if attempt > p.MaxRetries || !retryable(err) {
+		return 0, false
+	}
+
+	delay := float64(p.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Multiplier
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	if p.MaxElapsed > 0 && elapsed+time.Duration(delay) > p.MaxElapsed {
+		return 0, false
+	}
+	if p.OnRetry != nil {
+		p.OnRetry(attempt, err, time.Duration(delay))
+	}
+	return time.Duration(delay), true
+}
+
+// IsRetryable reports whether err is likely transient: timeouts, 5xx responses, network
+// failures such as refused or reset connections (common while the card is waking up),
+// DNS failures, connections dropped in the middle of a response, and truncated listings.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, ErrServerError) || errors.Is(err, ErrIncompleteListing) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// Refused, reset, and unreachable connections are all reported as *net.OpError
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+func (c *Client) retryOperation(ctx context.Context, operation func() error) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil {
+			return nil
+		}
+
+		delay, retry := c.retryPolicy.NextDelay(attempt, time.Since(start), err)
+		if !retry {
+			if attempt == 1 {
+				return err
+			}
+			return fmt.Errorf("operation failed after %d retries: %w", attempt-1, err)
+		}
+
+		if c.logger != nil {
+			c.logger.Printf("Retrying operation (attempt %d) after error: %v (waiting %v)", attempt, err, delay.Round(time.Millisecond))
+		}
+		c.logAttrs(ctx, slog.LevelWarn, "retrying operation",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"server error", fmt.Errorf("%w: HTTP 503", ErrServerError), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+		{"not found", ErrNotFound, false},
+		{"invalid response", ErrInvalidResponse, false},
+		{"unexpected EOF", fmt.Errorf("failed to write file: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", &url.Error{Op: "Get", URL: "http://192.168.4.1", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"DNS failure", &url.Error{Op: "Get", URL: "http://ezshare.card", Err: &net.DNSError{Err: "no such host", Name: "ezshare.card", IsNotFound: true}}, true},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBackoffPolicy_NextDelay(t *testing.T) {
+	policy := NewBackoffPolicy(3)
+	retryable := fmt.Errorf("%w: HTTP 500", ErrServerError)
+
+	for attempt, base := range []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second} {
+		delay, ok := policy.NextDelay(attempt+1, 0, retryable)
+		if !ok {
+			t.Fatalf("attempt %d: expected retry", attempt+1)
+		}
+		low := time.Duration(float64(base) * 0.75)
+		high := time.Duration(float64(base) * 1.25)
+		if delay < low || delay > high {
+			t.Errorf("attempt %d: delay %v outside [%v, %v]", attempt+1, delay, low, high)
+		}
+	}
+
+	if _, ok := policy.NextDelay(4, 0, retryable); ok {
+		t.Error("expected no retry after MaxRetries")
+	}
+	if _, ok := policy.NextDelay(1, 0, ErrNotFound); ok {
+		t.Error("expected no retry for non-retryable error")
+	}
+}
+
+func TestBackoffPolicy_MaxElapsed(t *testing.T) {
+	policy := NewBackoffPolicy(10)
+	policy.MaxElapsed = 5 * time.Second
+	policy.Jitter = 0
+
+	if _, ok := policy.NextDelay(1, time.Second, ErrServerError); !ok {
+		t.Error("expected retry within budget")
+	}
+	if _, ok := policy.NextDelay(2, 4500*time.Millisecond, ErrServerError); ok {
+		t.Error("expected no retry past MaxElapsed")
+	}
+}
+
+func TestBackoffPolicy_CustomClassifierAndHook(t *testing.T) {
+	errBusy := errors.New("card busy")
+	var hooked []int
+
+	policy := NewBackoffPolicy(2)
+	policy.Retryable = func(err error) bool {
+		return IsRetryable(err) || errors.Is(err, errBusy)
+	}
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) {
+		hooked = append(hooked, attempt)
+	}
+
+	if _, ok := policy.NextDelay(1, 0, errBusy); !ok {
+		t.Error("expected custom error to be retried")
+	}
+	if len(hooked) != 1 || hooked[0] != 1 {
+		t.Errorf("OnRetry called with %v, want [1]", hooked)
+	}
+}
+
+func fastRetryPolicy(maxRetries int) *BackoffPolicy {
+	policy := NewBackoffPolicy(maxRetries)
+	policy.InitialDelay = time.Millisecond
+	return policy
+}
+
+func TestGetFile_RetriesDroppedConnection(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Drop the connection without a response, as the card does when its Wi-Fi blips
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(2)))
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt"}
+
+	reader, err := client.GetFile(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	data, _ := io.ReadAll(reader)
+	if string(data) != "content" {
+		t.Errorf("unexpected content %q", data)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestRetryOperation_ReportsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := createTestClient(t, server.URL, WithRetryPolicy(fastRetryPolicy(2)))
+	entry := &Entry{Name: "test.txt", URL: server.URL + "/download?file=test.txt"}
+
+	_, err := client.GetFile(context.Background(), entry)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("expected ErrServerError, got %v", err)
+	}
+}
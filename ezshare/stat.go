@@ -0,0 +1,106 @@
+package ezshare
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Stat returns the entry for a single file or directory on the device. The final path
+// component is matched case-insensitively against both the display name and the DOS
+// 8.3 name, as FAT itself does. If the path does not exist, the returned error wraps
+// ErrNotFound.
+func (c *Client) Stat(ctx context.Context, filePath string) (*Entry, error) {
+	filePath = cleanPath(filePath)
+	if filePath == "/" {
+		fw, err := c.DetectFirmware(ctx)
+		if err != nil {
+			return nil, opError("stat", filePath, err)
+		}
+		return &Entry{Name: "/", Path: "/", IsDir: true, RemotePath: fw.APIPath("/")}, nil
+	}
+
+	entries, err := c.ListDirectory(ctx, path.Dir(filePath))
+	if err != nil {
+		return nil, opError("stat", filePath, err)
+	}
+
+	base := path.Base(filePath)
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Name, base) || strings.EqualFold(entry.ShortName, base) {
+			return entry, nil
+		}
+	}
+	return nil, &OpError{Op: "stat", Path: filePath, Err: ErrNotFound}
+}
+
+// resolveAPIPath converts a Unix-style path to the DOS path the device understands.
+// Paths made of 8.3 names are converted directly. Long names can't be used in device
+// URLs, so those paths are resolved through the listing of their parent directory.
+func (c *Client) resolveAPIPath(ctx context.Context, unixPath string) (string, error) {
+	unixPath = cleanPath(unixPath)
+	if !needsShortNames(unixPath) {
+		fw, err := c.DetectFirmware(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fw.APIPath(unixPath), nil
+	}
+	entry, err := c.Stat(ctx, unixPath)
+	if err != nil {
+		return "", err
+	}
+	return entry.RemotePath, nil
+}
+
+// needsShortNames reports whether any component of a Unix-style path is a long name.
+func needsShortNames(unixPath string) bool {
+	for _, part := range strings.Split(strings.Trim(unixPath, "/"), "/") {
+		if part != "" && !isValid83(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// isValid83 reports whether name is a valid DOS 8.3 name, ignoring case.
+func isValid83(name string) bool {
+	base, ext, _ := strings.Cut(name, ".")
+	if base == "" || len(base) > 8 || len(ext) > 3 || strings.Contains(ext, ".") {
+		return false
+	}
+	for _, r := range strings.ToUpper(base + ext) {
+		valid := r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'()-@^_`{}~", r)
+		if !valid {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHref extracts the 8.3 name and the full DOS path of an entry from the file= or
+// dir= query parameter of its listing link.
+func parseHref(href string) (shortName, remotePath string) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", ""
+	}
+	query := u.Query()
+	apiPath := query.Get("file")
+	if apiPath == "" {
+		apiPath = query.Get("dir")
+	}
+	if apiPath == "" {
+		return "", ""
+	}
+
+	apiPath = decodeDeviceString(apiPath)
+	if strings.EqualFold(apiPath, "A:") {
+		return "", "A:"
+	}
+	if len(apiPath) < 2 || !strings.EqualFold(apiPath[:2], "A:") {
+		apiPath = "A:\\" + apiPath
+	}
+	return apiPath[strings.LastIndex(apiPath, "\\")+1:], apiPath
+}
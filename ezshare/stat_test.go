@@ -0,0 +1,153 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStat(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"/STR.edf":            "str",
+		"/Identification.tgt": "id",
+		"/DATALOG/20260104/20260104_234156_BRP.edf": "brp",
+	})
+
+	tests := []struct {
+		path     string
+		wantName string
+		wantDir  bool
+	}{
+		{"/STR.edf", "STR.edf", false},
+		{"/str.EDF", "STR.edf", false},
+		{"Identification.tgt", "Identification.tgt", false},
+		{"/IDENTI~1.TGT", "Identification.tgt", false},
+		{"/identi~1.tgt", "Identification.tgt", false},
+		{"/DATALOG", "DATALOG", true},
+		{"/datalog/20260104/", "20260104", true},
+		{"/DATALOG/20260104/202601~1.EDF", "20260104_234156_BRP.edf", false},
+		{"/", "/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			entry, err := client.Stat(context.Background(), tt.path)
+			if err != nil {
+				t.Fatalf("Stat(%q) failed: %v", tt.path, err)
+			}
+			if entry.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", entry.Name, tt.wantName)
+			}
+			if entry.IsDir != tt.wantDir {
+				t.Errorf("IsDir = %v, want %v", entry.IsDir, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestStat_NotFound(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+
+	for _, p := range []string{"/missing.edf", "/MISSING/STR.edf"} {
+		if _, err := client.Stat(context.Background(), p); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat(%q) error = %v, want ErrNotFound", p, err)
+		}
+	}
+}
+
+func TestParseHref(t *testing.T) {
+	tests := []struct {
+		input          string
+		wantShortName  string
+		wantRemotePath string
+	}{
+		{"http://192.168.4.1/download?file=STR.EDF", "STR.EDF", "A:\\STR.EDF"},
+		{"http://192.168.4.1/download?file=DATALOG%5C20260104%5C20FL2G~1.EDF", "20FL2G~1.EDF", "A:\\DATALOG\\20260104\\20FL2G~1.EDF"},
+		{"dir?dir=A:%5CDATALOG", "DATALOG", "A:\\DATALOG"},
+		{"dir?dir=A:", "", "A:"},
+		{"photo", "", ""},
+	}
+
+	for _, tt := range tests {
+		shortName, remotePath := parseHref(tt.input)
+		if shortName != tt.wantShortName || remotePath != tt.wantRemotePath {
+			t.Errorf("parseHref(%q) = %q, %q; want %q, %q", tt.input, shortName, remotePath, tt.wantShortName, tt.wantRemotePath)
+		}
+	}
+}
+
+func TestNeedsShortNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"/", false},
+		{"/DATALOG/20260104", false},
+		{"/STR.edf", false},
+		{"/datalog/str.edf", false},
+		{"/IDNK8C~1.TGT", false},
+		{"/Identification.tgt", true},
+		{"/DATALOG/20260104/20260104_234156_BRP.edf", true},
+		{"/System Volume Information", true},
+		{"/archive.tar.gz", true},
+		{"/照片", true},
+	}
+
+	for _, tt := range tests {
+		if got := needsShortNames(tt.input); got != tt.expected {
+			t.Errorf("needsShortNames(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLongNamePaths(t *testing.T) {
+	_, client := setupTestCard(t, map[string]string{
+		"/DATALOG/20260104/20260104_234156_BRP.edf":    "brp",
+		"/System Volume Information/IndexerVolumeGuid": "guid",
+	})
+
+	destPath := filepath.Join(t.TempDir(), "brp.edf")
+	if err := client.DownloadFileByPath(context.Background(), "/DATALOG/20260104/20260104_234156_BRP.edf", destPath); err != nil {
+		t.Fatalf("DownloadFileByPath with long name failed: %v", err)
+	}
+	if data, _ := os.ReadFile(destPath); string(data) != "brp" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	shortDest := filepath.Join(t.TempDir(), "brp-short.edf")
+	if err := client.DownloadFileByPath(context.Background(), "/DATALOG/20260104/202601~1.EDF", shortDest); err != nil {
+		t.Fatalf("DownloadFileByPath with 8.3 name failed: %v", err)
+	}
+
+	entries, err := client.ListDirectory(context.Background(), "/System Volume Information")
+	if err != nil {
+		t.Fatalf("ListDirectory with long name failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "/System Volume Information/IndexerVolumeGuid" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	if entries[0].ShortName != "INDEXE~1" || entries[0].RemotePath != "A:\\SYSTEM~1\\INDEXE~1" {
+		t.Errorf("unexpected short name %q / remote path %q", entries[0].ShortName, entries[0].RemotePath)
+	}
+
+	if _, err := client.ListDirectory(context.Background(), "/SYSTEM~1"); err != nil {
+		t.Errorf("ListDirectory with 8.3 name failed: %v", err)
+	}
+}
+
+func TestStat_RootUsesFirmwareDriver(t *testing.T) {
+	server, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	fw := &apiPathFirmware{Firmware: FirmwareLZ1801EDPG}
+	client := createTestClient(t, server.URL, WithHTTPClient(server.Client()), WithFirmware(fw))
+
+	entry, err := client.Stat(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if entry.RemotePath != "A:" || !slices.Contains(fw.paths, "/") {
+		t.Errorf("RemotePath = %q, paths converted by the driver %v", entry.RemotePath, fw.paths)
+	}
+}
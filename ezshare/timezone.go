@@ -0,0 +1,51 @@
+package ezshare
+
+import "time"
+
+// deviceTime converts a wall-clock time read from the device, parsed as if it were UTC, to
+// the instant it denotes in loc.
+//
+// Around daylight saving time transitions, a wall-clock time can be ambiguous (it occurs
+// twice when clocks go back) or nonexistent (it is skipped when clocks go forward). An
+// ambiguous time resolves to its first occurrence. A nonexistent time is interpreted with
+// the offset in effect before the transition, which moves it forward by the length of the
+// gap: 02:30 in a one-hour gap becomes 03:30.
+func deviceTime(wall time.Time, loc *time.Location) time.Time {
+	if loc == nil || loc == time.UTC {
+		return wall
+	}
+
+	// The offsets in effect a day before and after; a transition in between is the only
+	// way a wall-clock time can have zero or two valid interpretations.
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	before := wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	after := wall.Add(-time.Duration(offsetAfter) * time.Second).In(loc)
+	beforeValid := sameWallClock(before, wall)
+	afterValid := sameWallClock(after, wall)
+
+	switch {
+	case beforeValid && afterValid:
+		if after.Before(before) {
+			return after
+		}
+		return before
+	case afterValid:
+		return after
+	default:
+		// Valid with the earlier offset, or nonexistent: use the offset before the gap
+		return before
+	}
+}
+
+// sameWallClock reports whether t, in its own location, shows the same wall-clock time as
+// wall does in UTC.
+func sameWallClock(t, wall time.Time) bool {
+	return wallClock(t).Equal(wall)
+}
+
+// wallClock returns the wall-clock time of t in its location, as a UTC time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,83 @@
+package ezshare
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeviceTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		wall time.Time
+		want time.Time
+	}{
+		{
+			name: "winter",
+			wall: time.Date(2026, 1, 5, 5, 8, 56, 0, time.UTC),
+			want: time.Date(2026, 1, 5, 10, 8, 56, 0, time.UTC),
+		},
+		{
+			name: "summer",
+			wall: time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 7, 1, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			// Clocks go from 02:00 EST to 03:00 EDT; 02:30 does not exist
+			name: "nonexistent",
+			wall: time.Date(2026, 3, 8, 2, 30, 0, 0, time.UTC),
+			want: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+		},
+		{
+			// Clocks go from 02:00 EDT back to 01:00 EST; 01:30 occurs twice
+			name: "ambiguous",
+			wall: time.Date(2026, 11, 1, 1, 30, 0, 0, time.UTC),
+			want: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+		},
+		{
+			name: "after fall back",
+			wall: time.Date(2026, 11, 1, 2, 30, 0, 0, time.UTC),
+			want: time.Date(2026, 11, 1, 7, 30, 0, 0, time.UTC), // 02:30 EST
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deviceTime(tt.wall, newYork)
+			if !got.Equal(tt.want) {
+				t.Errorf("deviceTime(%v) = %v, want %v", tt.wall, got.UTC(), tt.want)
+			}
+			if got.Location() != newYork {
+				t.Errorf("location = %v, want %v", got.Location(), newYork)
+			}
+		})
+	}
+}
+
+func TestDeviceTime_FixedZone(t *testing.T) {
+	wall := time.Date(2026, 1, 5, 5, 8, 56, 0, time.UTC)
+	got := deviceTime(wall, time.FixedZone("UTC+2", 2*60*60))
+	if want := time.Date(2026, 1, 5, 3, 8, 56, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("deviceTime = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestListDirectory_DeviceLocation(t *testing.T) {
+	card, _ := setupTestCard(t, map[string]string{"/STR.edf": "str"})
+	tokyo := time.FixedZone("JST", 9*60*60)
+	card.Handler.Location = tokyo
+
+	client := createTestClient(t, card.URL, WithHTTPClient(card.Client()), WithDeviceLocation(tokyo))
+	entries, err := client.ListDirectory(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if want := time.Date(2026, 1, 4, 23, 41, 40, 0, time.UTC); !entries[0].Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", entries[0].Timestamp, want)
+	}
+}
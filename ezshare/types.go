@@ -4,18 +4,52 @@ import "time"
 
 // Entry represents a file or directory on the EZ-Share device.
 type Entry struct {
-	Name      string
-	IsDir     bool
+	Name string
+	// Path is the full Unix-style path of the entry on the device (e.g. "/DATALOG/20260104").
+	Path  string
+	IsDir bool
+	// Timestamp is the modification time, read from the device's clock in the location
+	// set with WithDeviceLocation.
 	Timestamp time.Time
 	Size      int64
-	URL       string
+	// URL is the link from the listing, resolved against the client's base URL.
+	URL string
+	// ShortName is the DOS 8.3 name of the entry (e.g. "20FL2G~1.EDF"). The device uses
+	// 8.3 names in its URLs, while Name is the long name shown in listings.
+	ShortName string
+	// RemotePath is the full DOS path of the entry built from 8.3 names, as found in the
+	// listing link (e.g. `A:\DATALOG\20260104\20FL2G~1.EDF`).
+	RemotePath string
+
+	// The following fields are only known after a request to the download endpoint,
+	// and are filled in by Client.Head. Size above is rounded up to KB by the device;
+	// ExactSize is the exact size in bytes, or zero if unknown.
+	ExactSize   int64
+	ETag        string
+	ContentType string
+	// ServerFilename is the file name from the Content-Disposition header (the device
+	// reports the 8.3 name in lowercase).
+	ServerFilename string
+}
+
+// Listing is a parsed directory listing.
+type Listing struct {
+	Entries []*Entry
+	// Warnings holds the listing lines that could not be parsed. With WithLenientListings
+	// they are skipped and the rest of the directory is returned; otherwise the first one
+	// fails the listing.
+	Warnings []*ParseError
+	// TotalEntries and TotalSize are the totals from the listing footer, as reported by
+	// the card; they may not match the entries. TotalSize is in bytes, rounded up to KB.
+	TotalEntries int
+	TotalSize    int64
 }
 
 // Version represents the firmware version information from the EZ-Share device.
 type Version struct {
-	ChipModel        string
-	FirmwareVersion  string
-	Date             string
-	BuildNumber      string
-	Raw              string
+	ChipModel       string
+	FirmwareVersion string
+	Date            string
+	BuildNumber     string
+	Raw             string
 }
@@ -1,12 +1,15 @@
 package ezshare
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+
+	"golang.org/x/net/html/charset"
 )
 
 type versionResponse struct {
@@ -28,7 +31,7 @@ func (c *Client) GetVersion(ctx context.Context) (*Version, error) {
 		}
 		return err
 	})
-	return version, err
+	return version, opError("version", "", err)
 }
 
 func (c *Client) getVersionAttempt(ctx context.Context) (*Version, error) {
@@ -39,14 +42,14 @@ func (c *Client) getVersionAttempt(ctx context.Context) (*Version, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, req)
+	resp, err := c.doRequest(ctx, "version", req)
 	if err != nil {
 		return nil, fmt.Errorf("version request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, statusError("version", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -55,12 +58,14 @@ func (c *Client) getVersionAttempt(ctx context.Context) (*Version, error) {
 	}
 
 	var versionResp versionResponse
-	if err := xml.Unmarshal(body, &versionResp); err != nil {
-		return nil, fmt.Errorf("failed to parse XML response: %w", err)
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(&versionResp); err != nil {
+		return nil, &ParseError{Reason: "malformed XML response", Err: err}
 	}
 
 	if versionResp.Device.Version == "" {
-		return nil, fmt.Errorf("%w: version string is empty", ErrInvalidResponse)
+		return nil, &ParseError{Reason: "version string is empty"}
 	}
 
 	version, err := parseVersionString(versionResp.Device.Version)
@@ -74,12 +79,12 @@ func (c *Client) getVersionAttempt(ctx context.Context) (*Version, error) {
 func parseVersionString(versionStr string) (*Version, error) {
 	parts := strings.Fields(versionStr)
 	if len(parts) == 0 {
-		return nil, fmt.Errorf("%w: empty version string", ErrInvalidResponse)
+		return nil, &ParseError{Reason: "empty version string"}
 	}
 
 	components := strings.Split(parts[0], ":")
 	if len(components) != 4 {
-		return nil, fmt.Errorf("%w: expected 4 components, got %d", ErrInvalidResponse, len(components))
+		return nil, &ParseError{Line: parts[0], Reason: fmt.Sprintf("expected 4 components, got %d", len(components))}
 	}
 
 	return &Version{
@@ -0,0 +1,81 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// SkipDir is used as a return value from WalkFunc to indicate that the directory named
+// in the call is to be skipped. It is the same error as fs.SkipDir.
+var SkipDir = fs.SkipDir
+
+// SkipAll is used as a return value from WalkFunc to indicate that all remaining files
+// and directories are to be skipped. It is the same error as fs.SkipAll.
+var SkipAll = fs.SkipAll
+
+// WalkFunc is the type of the function called by Client.Walk to visit each file or
+// directory. It follows the semantics of fs.WalkDirFunc: path is the full Unix-style
+// path of the entry, and err reports a failure to list the directory at path. If the
+// root itself can't be found, fn is called once with a nil entry and the error.
+//
+// If the function returns SkipDir for a directory, Walk skips its contents; for a file,
+// Walk skips the remaining entries of the containing directory. Returning SkipAll stops
+// the walk. Any other non-nil error stops the walk and is returned by Walk.
+type WalkFunc func(path string, entry *Entry, err error) error
+
+// Walk walks the directory tree rooted at root, calling fn for each file or directory,
+// including root itself. Like fs.WalkDir, it looks up root with Stat first, so a file
+// root is visited as a file. Entries are visited in listing order. Walk does not follow
+// the order of any particular filesystem and does not sort entries.
+//
+// A failure to list a directory is reported by a second call to fn for that directory,
+// with a non-nil err; if fn returns nil, the walk continues with the next entry.
+func (c *Client) Walk(ctx context.Context, root string, fn WalkFunc) error {
+	root = cleanPath(root)
+	rootEntry, err := c.Stat(ctx, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = c.walkDir(ctx, root, rootEntry, fn)
+	}
+	if errors.Is(err, SkipDir) || errors.Is(err, SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func (c *Client) walkDir(ctx context.Context, dirPath string, dir *Entry, fn WalkFunc) error {
+	if err := fn(dirPath, dir, nil); err != nil || !dir.IsDir {
+		if errors.Is(err, SkipDir) && dir.IsDir {
+			err = nil
+		}
+		return err
+	}
+
+	listing, err := c.ListEntry(ctx, dir)
+	if err != nil {
+		if err = fn(dirPath, dir, err); err != nil {
+			if errors.Is(err, SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	if listing == nil {
+		return nil
+	}
+	for _, entry := range listing.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.walkDir(ctx, entry.Path, entry, fn); err != nil {
+			if errors.Is(err, SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
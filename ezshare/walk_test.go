@@ -0,0 +1,211 @@
+package ezshare
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var walkTestFiles = map[string]string{
+	"/STR.edf": "str",
+	"/DATALOG/20260104/20260104_234139_CSL.edf": "csl",
+	"/DATALOG/20260104/20260104_234156_BRP.edf": "brp",
+	"/DATALOG/20260105/20260105_230000_EVE.edf": "eve",
+	"/SETTINGS/CurrentSettings.json":            "{}",
+}
+
+func collectWalk(t *testing.T, client *Client, root string, fn func(path string, entry *Entry) error) []string {
+	t.Helper()
+	var visited []string
+	err := client.Walk(context.Background(), root, func(path string, entry *Entry, err error) error {
+		if err != nil {
+			t.Fatalf("unexpected walk error at %s: %v", path, err)
+		}
+		if entry.Path != path {
+			t.Errorf("entry.Path = %q, want %q", entry.Path, path)
+		}
+		visited = append(visited, path)
+		if fn != nil {
+			return fn(path, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	return visited
+}
+
+func TestWalk(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	got := collectWalk(t, client, "/", nil)
+	want := []string{
+		"/",
+		"/DATALOG",
+		"/DATALOG/20260104",
+		"/DATALOG/20260104/20260104_234139_CSL.edf",
+		"/DATALOG/20260104/20260104_234156_BRP.edf",
+		"/DATALOG/20260105",
+		"/DATALOG/20260105/20260105_230000_EVE.edf",
+		"/SETTINGS",
+		"/SETTINGS/CurrentSettings.json",
+		"/STR.edf",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestWalk_Subtree(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	got := collectWalk(t, client, "DATALOG/20260105/", nil)
+	want := []string{"/DATALOG/20260105", "/DATALOG/20260105/20260105_230000_EVE.edf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestWalk_FileRoot(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	var entries []*Entry
+	got := collectWalk(t, client, "/STR.edf", func(_ string, entry *Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if !reflect.DeepEqual(got, []string{"/STR.edf"}) {
+		t.Errorf("Walk visited %v, want [/STR.edf]", got)
+	}
+	if len(entries) == 1 && entries[0].IsDir {
+		t.Error("a file root should be visited as a file")
+	}
+}
+
+func TestWalk_MissingRoot(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	calls := 0
+	err := client.Walk(context.Background(), "/MISSING", func(path string, entry *Entry, err error) error {
+		calls++
+		if path != "/MISSING" || entry != nil || !errors.Is(err, ErrNotFound) {
+			t.Errorf("fn(%q, %v, %v), want the root with a nil entry and ErrNotFound", path, entry, err)
+		}
+		return err
+	})
+	if calls != 1 || !errors.Is(err, ErrNotFound) {
+		t.Errorf("fn called %d times, Walk returned %v", calls, err)
+	}
+}
+
+func TestWalk_SkipDir(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	got := collectWalk(t, client, "/", func(path string, entry *Entry) error {
+		if path == "/DATALOG" {
+			return SkipDir
+		}
+		if path == "/DATALOG/20260104/20260104_234139_CSL.edf" {
+			t.Error("visited file inside skipped directory")
+		}
+		return nil
+	})
+	want := []string{"/", "/DATALOG", "/SETTINGS", "/SETTINGS/CurrentSettings.json", "/STR.edf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestWalk_SkipDirOnFile(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	got := collectWalk(t, client, "/DATALOG", func(path string, entry *Entry) error {
+		if path == "/DATALOG/20260104/20260104_234139_CSL.edf" {
+			return SkipDir
+		}
+		return nil
+	})
+	want := []string{
+		"/DATALOG",
+		"/DATALOG/20260104",
+		"/DATALOG/20260104/20260104_234139_CSL.edf",
+		"/DATALOG/20260105",
+		"/DATALOG/20260105/20260105_230000_EVE.edf",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestWalk_SkipAll(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	got := collectWalk(t, client, "/", func(path string, entry *Entry) error {
+		if path == "/DATALOG/20260104" {
+			return SkipAll
+		}
+		return nil
+	})
+	want := []string{"/", "/DATALOG", "/DATALOG/20260104"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+}
+
+func TestWalk_ListingErrorReported(t *testing.T) {
+	server, _ := setupTestCard(t, walkTestFiles)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dir" && r.URL.Query().Get("dir") == "A:\\DATALOG\\20260104" {
+			http.NotFound(w, r)
+			return
+		}
+		server.Handler.ServeHTTP(w, r)
+	}))
+	defer failing.Close()
+	client := createTestClient(t, failing.URL)
+
+	var visited []string
+	var errorPaths []string
+	err := client.Walk(context.Background(), "/DATALOG", func(path string, entry *Entry, err error) error {
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			errorPaths = append(errorPaths, path)
+			return nil
+		}
+		visited = append(visited, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(errorPaths, []string{"/DATALOG/20260104"}) {
+		t.Errorf("errors reported for %v, want [/DATALOG/20260104]", errorPaths)
+	}
+	want := []string{"/DATALOG", "/DATALOG/20260104", "/DATALOG/20260105", "/DATALOG/20260105/20260105_230000_EVE.edf"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk visited %v, want %v", visited, want)
+	}
+}
+
+func TestWalk_ErrorStopsWalk(t *testing.T) {
+	_, client := setupTestCard(t, walkTestFiles)
+
+	stop := errors.New("stop")
+	err := client.Walk(context.Background(), "/", func(path string, entry *Entry, err error) error {
+		if path == "/DATALOG/20260104" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Walk returned %v, want %v", err, stop)
+	}
+}
@@ -2,4 +2,8 @@ module github.com/haimgel/ezshare-sync
 
 go 1.25.1
 
-require golang.org/x/net v0.48.0
+require (
+	golang.org/x/net v0.48.0
+	golang.org/x/text v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
+)